@@ -67,14 +67,18 @@ type BackupOptions struct {
 	Parent            string
 	GroupBy           restic.SnapshotGroupByOptions
 	Force             bool
+	RequireParent     bool
 	ExcludeOtherFS    bool
 	ExcludeIfPresent  []string
 	ExcludeCaches     bool
 	ExcludeLargerThan string
+	ExcludeNewerThan  string
+	ResticIgnore      bool
 	Stdin             bool
 	StdinFilename     string
 	StdinCommand      bool
 	Tags              restic.TagLists
+	Annotations       []string
 	Host              string
 	FilesFrom         []string
 	FilesFromVerbatim []string
@@ -94,6 +98,10 @@ var backupOptions BackupOptions
 // ErrInvalidSourceData is used to report an incomplete backup
 var ErrInvalidSourceData = errors.New("at least one source file could not be read")
 
+// ErrNoParentSnapshot is returned if --require-parent was specified, but no
+// parent snapshot could be found
+var ErrNoParentSnapshot = errors.Fatal("no parent snapshot found, but --require-parent was specified")
+
 func init() {
 	cmdRoot.AddCommand(cmdBackup)
 
@@ -102,17 +110,21 @@ func init() {
 	backupOptions.GroupBy = restic.SnapshotGroupByOptions{Host: true, Path: true}
 	f.VarP(&backupOptions.GroupBy, "group-by", "g", "`group` snapshots by host, paths and/or tags, separated by comma (disable grouping with '')")
 	f.BoolVarP(&backupOptions.Force, "force", "f", false, `force re-reading the target files/directories (overrides the "parent" flag)`)
+	f.BoolVar(&backupOptions.RequireParent, "require-parent", false, "abort the backup if no parent snapshot is found instead of reading all files")
 
 	initExcludePatternOptions(f, &backupOptions.excludePatternOptions)
 
 	f.BoolVarP(&backupOptions.ExcludeOtherFS, "one-file-system", "x", false, "exclude other file systems, don't cross filesystem boundaries and subvolumes")
 	f.StringArrayVar(&backupOptions.ExcludeIfPresent, "exclude-if-present", nil, "takes `filename[:header]`, exclude contents of directories containing filename (except filename itself) if header of that file is as provided (can be specified multiple times)")
 	f.BoolVar(&backupOptions.ExcludeCaches, "exclude-caches", false, `excludes cache directories that are marked with a CACHEDIR.TAG file. See https://bford.info/cachedir/ for the Cache Directory Tagging Standard`)
+	f.StringVar(&backupOptions.ExcludeNewerThan, "exclude-newer-than", "", "exclude files modified within the last `duration` before the backup started (e.g. 30s, 5m)")
+	f.BoolVar(&backupOptions.ResticIgnore, "resticignore", false, "honor exclude patterns in .resticignore files, which apply to the directory containing them and below")
 	f.StringVar(&backupOptions.ExcludeLargerThan, "exclude-larger-than", "", "max `size` of the files to be backed up (allowed suffixes: k/K, m/M, g/G, t/T)")
 	f.BoolVar(&backupOptions.Stdin, "stdin", false, "read backup from stdin")
 	f.StringVar(&backupOptions.StdinFilename, "stdin-filename", "stdin", "`filename` to use when reading from stdin")
 	f.BoolVar(&backupOptions.StdinCommand, "stdin-from-command", false, "interpret arguments as command to execute and store its stdout")
 	f.Var(&backupOptions.Tags, "tag", "add `tags` for the new snapshot in the format `tag[,tag,...]` (can be specified multiple times)")
+	f.StringArrayVar(&backupOptions.Annotations, "annotation", nil, "add an annotation in the format `key=value` to the new snapshot (can be specified multiple times)")
 	f.UintVar(&backupOptions.ReadConcurrency, "read-concurrency", 0, "read `n` files concurrently (default: $RESTIC_READ_CONCURRENCY or 2)")
 	f.StringVarP(&backupOptions.Host, "host", "H", "", "set the `hostname` for the snapshot manually (default: $RESTIC_HOST). To prevent an expensive rescan use the \"parent\" flag")
 	f.StringVar(&backupOptions.Host, "hostname", "", "set the `hostname` for the snapshot manually")
@@ -270,6 +282,15 @@ func (opts BackupOptions) Check(gopts GlobalOptions, args []string) error {
 		}
 	}
 
+	if opts.RequireParent {
+		if opts.Force {
+			return errors.Fatal("--require-parent and --force cannot be used together")
+		}
+		if opts.Stdin || opts.StdinCommand {
+			return errors.Fatal("--require-parent cannot be used when reading from stdin")
+		}
+	}
+
 	if opts.Stdin || opts.StdinCommand {
 		if len(opts.FilesFrom) > 0 {
 			return errors.Fatal("--stdin and --files-from cannot be used together")
@@ -321,6 +342,10 @@ func collectRejectByNameFuncs(opts BackupOptions, repo *repository.Repository) (
 		fs = append(fs, f)
 	}
 
+	if opts.ResticIgnore {
+		fs = append(fs, rejectByIgnoreFiles(resticIgnoreFilename))
+	}
+
 	return fs, nil
 }
 
@@ -344,6 +369,14 @@ func collectRejectFuncs(opts BackupOptions, targets []string) (fs []RejectFunc,
 		fs = append(fs, f)
 	}
 
+	if len(opts.ExcludeNewerThan) != 0 && !opts.Stdin {
+		f, err := rejectByMtime(opts.ExcludeNewerThan)
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+
 	return fs, nil
 }
 
@@ -473,6 +506,11 @@ func runBackup(ctx context.Context, opts BackupOptions, gopts GlobalOptions, ter
 		}
 	}
 
+	annotations, err := restic.ParseAnnotations(opts.Annotations)
+	if err != nil {
+		return errors.Fatal(err.Error())
+	}
+
 	if gopts.verbosity >= 2 && !gopts.JSON {
 		Verbosef("open repository\n")
 	}
@@ -511,6 +549,9 @@ func runBackup(ctx context.Context, opts BackupOptions, gopts GlobalOptions, ter
 		if err != nil {
 			return err
 		}
+		if parentSnapshot == nil && opts.RequireParent {
+			return ErrNoParentSnapshot
+		}
 
 		if !gopts.JSON {
 			if parentSnapshot != nil {
@@ -640,6 +681,7 @@ func runBackup(ctx context.Context, opts BackupOptions, gopts GlobalOptions, ter
 	snapshotOpts := archiver.SnapshotOptions{
 		Excludes:       opts.Excludes,
 		Tags:           opts.Tags.Flatten(),
+		Annotations:    annotations,
 		BackupStart:    backupStart,
 		Time:           timeStamp,
 		Hostname:       opts.Host,
@@ -9,6 +9,7 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/restic/restic/internal/errors"
 	"github.com/restic/restic/internal/fs"
 	"github.com/restic/restic/internal/restic"
 	rtest "github.com/restic/restic/internal/test"
@@ -135,6 +136,35 @@ func TestBackupParentSelection(t *testing.T) {
 	rtest.Assert(t, latestSn.Parent != nil && latestSn.Parent.Equal(firstSnapshotID), "third snapshot selected unexpected parent %v instead of %v", latestSn.Parent, firstSnapshotID)
 }
 
+func TestBackupRequireParent(t *testing.T) {
+	env, cleanup := withTestEnvironment(t)
+	defer cleanup()
+
+	testSetupBackupData(t, env)
+	opts := BackupOptions{RequireParent: true}
+
+	// no snapshot exists yet
+	err := testRunBackupAssumeFailure(t, filepath.Dir(env.testdata), []string{"testdata/0/0"}, opts, env.gopts)
+	rtest.Assert(t, errors.Is(err, ErrNoParentSnapshot), "expected ErrNoParentSnapshot, got %v", err)
+	testListSnapshots(t, env.gopts, 0)
+
+	opts.RequireParent = false
+	testRunBackup(t, filepath.Dir(env.testdata), []string{"testdata/0/0"}, opts, env.gopts)
+	firstSnapshotID := testListSnapshots(t, env.gopts, 1)[0]
+
+	// a different path has no matching parent
+	opts.RequireParent = true
+	err = testRunBackupAssumeFailure(t, filepath.Dir(env.testdata), []string{"testdata/0/tests"}, opts, env.gopts)
+	rtest.Assert(t, errors.Is(err, ErrNoParentSnapshot), "expected ErrNoParentSnapshot, got %v", err)
+	testListSnapshots(t, env.gopts, 1)
+
+	// same path uses the first snapshot as parent
+	testRunBackup(t, filepath.Dir(env.testdata), []string{"testdata/0/0"}, opts, env.gopts)
+	latestSn, _ := testRunSnapshots(t, env.gopts)
+	rtest.Assert(t, latestSn != nil, "missing latest snapshot")
+	rtest.Assert(t, latestSn.Parent != nil && latestSn.Parent.Equal(firstSnapshotID), "unexpected parent %v instead of %v", latestSn.Parent, firstSnapshotID)
+}
+
 func TestDryRunBackup(t *testing.T) {
 	env, cleanup := withTestEnvironment(t)
 	defer cleanup()
@@ -430,6 +460,26 @@ func TestBackupTags(t *testing.T) {
 		"expected parent to be %v, got %v", parent.ID, newest.Parent)
 }
 
+// nolint: staticcheck // false positive nil pointer dereference check
+func TestBackupAnnotations(t *testing.T) {
+	env, cleanup := withTestEnvironment(t)
+	defer cleanup()
+
+	testSetupBackupData(t, env)
+	opts := BackupOptions{Annotations: []string{"ticket=OPS-42", "env=prod"}}
+	testRunBackup(t, "", []string{env.testdata}, opts, env.gopts)
+	newest, _ := testRunSnapshots(t, env.gopts)
+
+	if newest == nil {
+		t.Fatal("expected a backup, got nil")
+	}
+	rtest.Equals(t, map[string]string{"ticket": "OPS-42", "env": "prod"}, newest.Annotations)
+
+	opts.Annotations = []string{"invalid"}
+	err := testRunBackupAssumeFailure(t, "", []string{env.testdata}, opts, env.gopts)
+	rtest.Assert(t, err != nil, "expected error for invalid annotation")
+}
+
 // nolint: staticcheck // false positive nil pointer dereference check
 func TestBackupProgramVersion(t *testing.T) {
 	env, cleanup := withTestEnvironment(t)
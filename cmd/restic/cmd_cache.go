@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/restic/restic/internal/cache"
 	"github.com/restic/restic/internal/errors"
 	"github.com/restic/restic/internal/fs"
+	"github.com/restic/restic/internal/restic"
 	"github.com/restic/restic/internal/ui"
 	"github.com/restic/restic/internal/ui/table"
 	"github.com/spf13/cobra"
@@ -22,22 +24,33 @@ var cmdCache = &cobra.Command{
 	Long: `
 The "cache" command allows listing and cleaning local cache directories.
 
+The "--keep-snapshot" option removes all files from the cache of the current
+repository which are not needed to access the given snapshot.
+
+The "--warm" option downloads all index and snapshot files of the current
+repository into the cache. With "--warm-tree-packs", the pack files containing
+tree blobs are downloaded as well. This speeds up subsequent restores or
+mounts.
+
 EXIT STATUS
 ===========
 
 Exit status is 0 if the command was successful, and non-zero if there was any error.
 `,
 	DisableAutoGenTag: true,
-	RunE: func(_ *cobra.Command, args []string) error {
-		return runCache(cacheOptions, globalOptions, args)
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runCache(cmd.Context(), cacheOptions, globalOptions, args)
 	},
 }
 
 // CacheOptions bundles all options for the snapshots command.
 type CacheOptions struct {
-	Cleanup bool
-	MaxAge  uint
-	NoSize  bool
+	Cleanup       bool
+	MaxAge        uint
+	NoSize        bool
+	KeepSnapshot  string
+	Warm          bool
+	WarmTreePacks bool
 }
 
 var cacheOptions CacheOptions
@@ -49,9 +62,12 @@ func init() {
 	f.BoolVar(&cacheOptions.Cleanup, "cleanup", false, "remove old cache directories")
 	f.UintVar(&cacheOptions.MaxAge, "max-age", 30, "max age in `days` for cache directories to be considered old")
 	f.BoolVar(&cacheOptions.NoSize, "no-size", false, "do not output the size of the cache directories")
+	f.StringVar(&cacheOptions.KeepSnapshot, "keep-snapshot", "", "remove all files from the repository cache except those needed by `snapshotID`")
+	f.BoolVar(&cacheOptions.Warm, "warm", false, "download all index and snapshot files into the repository cache")
+	f.BoolVar(&cacheOptions.WarmTreePacks, "warm-tree-packs", false, "also download all pack files containing trees into the repository cache (implies --warm)")
 }
 
-func runCache(opts CacheOptions, gopts GlobalOptions, args []string) error {
+func runCache(ctx context.Context, opts CacheOptions, gopts GlobalOptions, args []string) error {
 	if len(args) > 0 {
 		return errors.Fatal("the cache command expects no arguments, only options - please see `restic help cache` for usage and flags")
 	}
@@ -72,6 +88,20 @@ func runCache(opts CacheOptions, gopts GlobalOptions, args []string) error {
 		}
 	}
 
+	if opts.KeepSnapshot != "" {
+		if opts.Cleanup {
+			return errors.Fatal("--keep-snapshot and --cleanup cannot be used together")
+		}
+		return runCacheKeepSnapshot(ctx, opts, gopts)
+	}
+
+	if opts.Warm || opts.WarmTreePacks {
+		if opts.Cleanup {
+			return errors.Fatal("--warm and --cleanup cannot be used together")
+		}
+		return runCacheWarm(ctx, opts, gopts)
+	}
+
 	if opts.Cleanup || gopts.CleanupCache {
 		oldDirs, err := cache.OlderThan(cachedir, time.Duration(opts.MaxAge)*24*time.Hour)
 		if err != nil {
@@ -161,6 +191,67 @@ func runCache(opts CacheOptions, gopts GlobalOptions, args []string) error {
 	return nil
 }
 
+func runCacheKeepSnapshot(ctx context.Context, opts CacheOptions, gopts GlobalOptions) error {
+	ctx, repo, unlock, err := openWithReadLock(ctx, gopts, gopts.NoLock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if repo.Cache == nil {
+		return errors.Fatal("the repository does not use a cache")
+	}
+
+	sn, _, err := restic.FindSnapshot(ctx, repo, repo, opts.KeepSnapshot)
+	if err != nil {
+		return errors.Fatalf("failed to find snapshot: %v", err)
+	}
+
+	bar := newIndexProgress(gopts.Quiet, gopts.JSON)
+	if err = repo.LoadIndex(ctx, bar); err != nil {
+		return err
+	}
+
+	keep, err := cache.SnapshotFiles(ctx, repo, sn)
+	if err != nil {
+		return err
+	}
+
+	Verbosef("keeping %d index files and %d pack files needed by snapshot %v\n",
+		len(keep[restic.IndexFile]), len(keep[restic.PackFile]), sn.ID().Str())
+
+	return repo.Cache.KeepOnly(keep)
+}
+
+func runCacheWarm(ctx context.Context, opts CacheOptions, gopts GlobalOptions) error {
+	ctx, repo, unlock, err := openWithReadLock(ctx, gopts, gopts.NoLock)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if repo.Cache == nil {
+		return errors.Fatal("the repository does not use a cache")
+	}
+
+	if opts.WarmTreePacks {
+		bar := newIndexProgress(gopts.Quiet, gopts.JSON)
+		if err = repo.LoadIndex(ctx, bar); err != nil {
+			return err
+		}
+	}
+
+	bar := newProgressMax(!gopts.Quiet && !gopts.JSON && stdoutIsTerminal(), 0, "files cached")
+	err = cache.WarmCache(ctx, repo, cache.WarmOptions{
+		Indexes:   true,
+		Snapshots: true,
+		TreePacks: opts.WarmTreePacks,
+		Progress:  bar,
+	})
+	bar.Done()
+	return err
+}
+
 func dirSize(path string) (int64, error) {
 	var size int64
 	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
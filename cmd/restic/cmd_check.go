@@ -45,10 +45,12 @@ Exit status is 0 if the command was successful, and non-zero if there was any er
 
 // CheckOptions bundles all options for the 'check' command.
 type CheckOptions struct {
-	ReadData       bool
-	ReadDataSubset string
-	CheckUnused    bool
-	WithCache      bool
+	ReadData                bool
+	ReadDataSubset          string
+	CheckUnused             bool
+	WithCache               bool
+	WarnSingleCopy          bool
+	WarnSingleCopySnapshots int
 }
 
 var checkOptions CheckOptions
@@ -67,12 +69,17 @@ func init() {
 		panic(err)
 	}
 	f.BoolVar(&checkOptions.WithCache, "with-cache", false, "use existing cache, only read uncached data from repository")
+	f.BoolVar(&checkOptions.WarnSingleCopy, "warn-single-copy", false, "warn about trees referenced by many snapshots which are stored in a single pack only")
+	f.IntVar(&checkOptions.WarnSingleCopySnapshots, "warn-single-copy-snapshots", 10, "minimum `number` of snapshots referencing a tree for --warn-single-copy")
 }
 
 func checkFlags(opts CheckOptions) error {
 	if opts.ReadData && opts.ReadDataSubset != "" {
 		return errors.Fatal("check flags --read-data and --read-data-subset cannot be used together")
 	}
+	if opts.WarnSingleCopy && opts.WarnSingleCopySnapshots < 1 {
+		return errors.Fatal("check flag --warn-single-copy-snapshots must be at least 1")
+	}
 	if opts.ReadDataSubset != "" {
 		dataSubset, err := stringToIntSlice(opts.ReadDataSubset)
 		argumentError := errors.Fatal("check flag --read-data-subset has invalid value, please see documentation")
@@ -117,6 +124,31 @@ func checkFlags(opts CheckOptions) error {
 const totalBucketsMax = 256
 
 // stringToIntSlice converts string to []uint, using '/' as element separator
+// printSingleCopyTrees warns about trees which are referenced by at least
+// minSnapshots snapshots but are only stored in a single pack file. These are
+// warnings and do not cause check to fail.
+func printSingleCopyTrees(ctx context.Context, chkr *checker.Checker, minSnapshots int) error {
+	Verbosef("check for single copies of trees referenced by at least %d snapshots\n", minSnapshots)
+	trees, err := chkr.SingleCopyTrees(ctx, minSnapshots)
+	if err != nil {
+		return err
+	}
+	if len(trees) == 0 {
+		return nil
+	}
+
+	packs := restic.NewIDSet()
+	for _, tree := range trees {
+		Verbosef("warning: %v\n", tree)
+		packs.Insert(tree.PackID)
+	}
+	Warnf("warning: %d trees referenced by at least %d snapshots are stored in only one copy in %d pack files.\n"+
+		"restic does not store data redundantly and relies on the storage backend to keep the pack files safe.\n"+
+		"Consider using a backend with built-in redundancy or keeping a second repository using 'restic copy'.\n",
+		len(trees), minSnapshots, len(packs))
+	return nil
+}
+
 func stringToIntSlice(param string) (split []uint, err error) {
 	if param == "" {
 		return nil, nil
@@ -323,6 +355,13 @@ func runCheck(ctx context.Context, opts CheckOptions, gopts GlobalOptions, args
 		return ctx.Err()
 	}
 
+	if opts.WarnSingleCopy {
+		err := printSingleCopyTrees(ctx, chkr, opts.WarnSingleCopySnapshots)
+		if err != nil {
+			return err
+		}
+	}
+
 	if opts.CheckUnused {
 		unused, err := chkr.UnusedBlobs(ctx)
 		if err != nil {
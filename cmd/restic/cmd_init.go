@@ -93,8 +93,9 @@ func runInit(ctx context.Context, opts InitOptions, gopts GlobalOptions, args []
 	}
 
 	s, err := repository.New(be, repository.Options{
-		Compression: gopts.Compression,
-		PackSize:    gopts.PackSize * 1024 * 1024,
+		Compression:  gopts.Compression,
+		PackSize:     gopts.PackSize * 1024 * 1024,
+		AutoPackSize: gopts.AutoPackSize,
 	})
 	if err != nil {
 		return errors.Fatal(err.Error())
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/restic/restic/internal/errors"
 	"github.com/restic/restic/internal/index"
@@ -16,6 +17,10 @@ var cmdList = &cobra.Command{
 	Long: `
 The "list" command allows listing objects in the repository based on type.
 
+For locks, the --long flag additionally prints the host, the process and the
+note of each lock. Long running operations periodically update the note with
+their progress.
+
 EXIT STATUS
 ===========
 
@@ -23,18 +28,31 @@ Exit status is 0 if the command was successful, and non-zero if there was any er
 `,
 	DisableAutoGenTag: true,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		return runList(cmd.Context(), globalOptions, args)
+		return runList(cmd.Context(), listOptions, globalOptions, args)
 	},
 }
 
+// ListOptions collects all options for the list command.
+type ListOptions struct {
+	Long bool
+}
+
+var listOptions ListOptions
+
 func init() {
 	cmdRoot.AddCommand(cmdList)
+
+	f := cmdList.Flags()
+	f.BoolVarP(&listOptions.Long, "long", "l", false, "print details for each lock (only for locks)")
 }
 
-func runList(ctx context.Context, gopts GlobalOptions, args []string) error {
+func runList(ctx context.Context, opts ListOptions, gopts GlobalOptions, args []string) error {
 	if len(args) != 1 {
 		return errors.Fatal("type not specified")
 	}
+	if opts.Long && args[0] != "locks" {
+		return errors.Fatal("--long is only supported for locks")
+	}
 
 	ctx, repo, unlock, err := openWithReadLock(ctx, gopts, gopts.NoLock || args[0] == "locks")
 	if err != nil {
@@ -67,8 +85,32 @@ func runList(ctx context.Context, gopts GlobalOptions, args []string) error {
 		return errors.Fatal("invalid type")
 	}
 
+	if opts.Long {
+		return restic.ForAllLocks(ctx, repo, nil, func(id restic.ID, lock *restic.Lock, err error) error {
+			if err != nil {
+				Printf("%s invalid lock: %v\n", id, err)
+				return nil
+			}
+			printLock(id, lock)
+			return nil
+		})
+	}
+
 	return repo.List(ctx, t, func(id restic.ID, _ int64) error {
 		Printf("%s\n", id)
 		return nil
 	})
 }
+
+func printLock(id restic.ID, lock *restic.Lock) {
+	mode := "shared"
+	if lock.Exclusive {
+		mode = "exclusive"
+	}
+	line := fmt.Sprintf("%s %s %s@%s PID %d refreshed %s", id, mode,
+		lock.Username, lock.Hostname, lock.PID, lock.Time.Format(TimeFormat))
+	if lock.Note != "" {
+		line += " note: " + lock.Note
+	}
+	Printf("%s\n", line)
+}
@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"math"
+	"os"
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/restic/restic/internal/debug"
 	"github.com/restic/restic/internal/errors"
@@ -25,6 +27,11 @@ var cmdPrune = &cobra.Command{
 The "prune" command checks the repository and removes data that is not
 referenced and therefore not needed any more.
 
+The "--plan-out" option only computes which packs would be repacked and removed
+and writes this plan to a file, without modifying the repository. After review,
+"--plan-in" executes exactly that plan. It aborts if the repository was modified
+in the meantime.
+
 EXIT STATUS
 ===========
 
@@ -42,6 +49,8 @@ Exit status is 0 if the command was successful, and non-zero if there was any er
 type PruneOptions struct {
 	DryRun                bool
 	UnsafeNoSpaceRecovery string
+	PlanOut               string
+	PlanIn                string
 
 	unsafeRecovery bool
 
@@ -54,6 +63,9 @@ type PruneOptions struct {
 	RepackCachableOnly bool
 	RepackSmall        bool
 	RepackUncompressed bool
+
+	RepackOlderThan restic.Duration
+	repackOlderThan time.Duration
 }
 
 var pruneOptions PruneOptions
@@ -63,6 +75,8 @@ func init() {
 	f := cmdPrune.Flags()
 	f.BoolVarP(&pruneOptions.DryRun, "dry-run", "n", false, "do not modify the repository, just print what would be done")
 	f.StringVarP(&pruneOptions.UnsafeNoSpaceRecovery, "unsafe-recover-no-free-space", "", "", "UNSAFE, READ THE DOCUMENTATION BEFORE USING! Try to recover a repository stuck with no free space. Do not use without trying out 'prune --max-repack-size 0' first.")
+	f.StringVar(&pruneOptions.PlanOut, "plan-out", "", "write the prune plan to `file` instead of executing it")
+	f.StringVar(&pruneOptions.PlanIn, "plan-in", "", "execute the prune plan from `file` created by --plan-out")
 	addPruneOptions(cmdPrune, &pruneOptions)
 }
 
@@ -73,6 +87,7 @@ func addPruneOptions(c *cobra.Command, pruneOptions *PruneOptions) {
 	f.BoolVar(&pruneOptions.RepackCachableOnly, "repack-cacheable-only", false, "only repack packs which are cacheable")
 	f.BoolVar(&pruneOptions.RepackSmall, "repack-small", false, "repack pack files below 80% of target pack size")
 	f.BoolVar(&pruneOptions.RepackUncompressed, "repack-uncompressed", false, "repack all uncompressed data")
+	f.Var(&pruneOptions.RepackOlderThan, "repack-older-than", "repack pack files stored longer ago than `duration` (eg. 1y5m7d2h), even if they are fully used")
 }
 
 func verifyPruneOptions(opts *PruneOptions) error {
@@ -89,6 +104,15 @@ func verifyPruneOptions(opts *PruneOptions) error {
 		opts.MaxRepackBytes = 0
 	}
 
+	if !opts.RepackOlderThan.Zero() {
+		d := opts.RepackOlderThan
+		now := time.Now()
+		opts.repackOlderThan = now.Sub(now.AddDate(-d.Years, -d.Months, -d.Days).Add(time.Hour * time.Duration(-d.Hours)))
+		if opts.repackOlderThan <= 0 {
+			return errors.Fatalf("invalid duration %v for --repack-older-than", d)
+		}
+	}
+
 	maxUnused := strings.TrimSpace(opts.MaxUnused)
 	if maxUnused == "" {
 		return errors.Fatalf("invalid value for --max-unused: %q", opts.MaxUnused)
@@ -144,6 +168,13 @@ func runPrune(ctx context.Context, opts PruneOptions, gopts GlobalOptions, term
 		return errors.Fatal("disabled compression and `--repack-uncompressed` are mutually exclusive")
 	}
 
+	if opts.PlanIn != "" && opts.PlanOut != "" {
+		return errors.Fatal("--plan-in and --plan-out cannot be used together")
+	}
+	if (opts.PlanIn != "" || opts.PlanOut != "") && opts.UnsafeNoSpaceRecovery != "" {
+		return errors.Fatal("--plan-in and --plan-out cannot be used with --unsafe-recover-no-free-space")
+	}
+
 	ctx, repo, unlock, err := openWithExclusiveLock(ctx, gopts, false)
 	if err != nil {
 		return err
@@ -179,6 +210,10 @@ func runPruneWithRepo(ctx context.Context, opts PruneOptions, gopts GlobalOption
 		return err
 	}
 
+	if opts.PlanIn != "" {
+		return runPruneFromPlan(ctx, opts, repo, printer)
+	}
+
 	popts := repository.PruneOptions{
 		DryRun:         opts.DryRun,
 		UnsafeRecovery: opts.unsafeRecovery,
@@ -189,6 +224,7 @@ func runPruneWithRepo(ctx context.Context, opts PruneOptions, gopts GlobalOption
 		RepackCachableOnly: opts.RepackCachableOnly,
 		RepackSmall:        opts.RepackSmall,
 		RepackUncompressed: opts.RepackUncompressed,
+		RepackOlderThan:    opts.repackOlderThan,
 	}
 
 	plan, err := repository.PlanPrune(ctx, popts, repo, func(ctx context.Context, repo restic.Repository) (usedBlobs restic.CountedBlobSet, err error) {
@@ -201,7 +237,7 @@ func runPruneWithRepo(ctx context.Context, opts PruneOptions, gopts GlobalOption
 		return ctx.Err()
 	}
 
-	if popts.DryRun {
+	if popts.DryRun || opts.PlanOut != "" {
 		printer.P("\nWould have made the following changes:")
 	}
 
@@ -210,12 +246,60 @@ func runPruneWithRepo(ctx context.Context, opts PruneOptions, gopts GlobalOption
 		return err
 	}
 
+	if opts.PlanOut != "" {
+		return savePrunePlan(ctx, opts.PlanOut, plan, printer)
+	}
+
 	// Trigger GC to reset garbage collection threshold
 	runtime.GC()
 
 	return plan.Execute(ctx, printer)
 }
 
+// savePrunePlan writes plan to the file filename.
+func savePrunePlan(ctx context.Context, filename string, plan *repository.PrunePlan, printer progress.Printer) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return errors.Fatalf("unable to create prune plan: %v", err)
+	}
+
+	err = plan.Save(ctx, f)
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	printer.P("prune plan written to %v\n", filename)
+	return nil
+}
+
+// runPruneFromPlan executes the prune plan stored in opts.PlanIn.
+func runPruneFromPlan(ctx context.Context, opts PruneOptions, repo *repository.Repository, printer progress.Printer) error {
+	f, err := os.Open(opts.PlanIn)
+	if err != nil {
+		return errors.Fatalf("unable to open prune plan: %v", err)
+	}
+	plan, err := repository.LoadPrunePlan(ctx, repo, f, repository.PruneOptions{DryRun: opts.DryRun})
+	_ = f.Close()
+	if err != nil {
+		return err
+	}
+
+	if opts.DryRun {
+		printer.P("\nWould have made the following changes:")
+	}
+
+	err = printPruneStats(printer, plan.Stats())
+	if err != nil {
+		return err
+	}
+
+	return plan.Execute(ctx, printer)
+}
+
 // printPruneStats prints out the statistics
 func printPruneStats(printer progress.Printer, stats repository.PruneStats) error {
 	printer.V("\nused:         %10d blobs / %s\n", stats.Blobs.Used, ui.FormatBytes(stats.Size.Used))
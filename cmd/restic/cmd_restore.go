@@ -51,8 +51,9 @@ type RestoreOptions struct {
 	InsensitiveInclude []string
 	Target             string
 	restic.SnapshotFilter
-	Sparse bool
-	Verify bool
+	Sparse        bool
+	SparseMinHole string
+	Verify        bool
 }
 
 var restoreOptions RestoreOptions
@@ -69,6 +70,7 @@ func init() {
 
 	initSingleSnapshotFilter(flags, &restoreOptions.SnapshotFilter)
 	flags.BoolVar(&restoreOptions.Sparse, "sparse", false, "restore files as sparse")
+	flags.StringVar(&restoreOptions.SparseMinHole, "sparse-min-hole", "", "restore all runs of zeros of at least `size` as holes (allowed suffixes: k/K, m/M, g/G, t/T), requires --sparse")
 	flags.BoolVar(&restoreOptions.Verify, "verify", false, "verify restored files content")
 }
 
@@ -123,6 +125,21 @@ func runRestore(ctx context.Context, opts RestoreOptions, gopts GlobalOptions,
 		return errors.Fatal("exclude and include patterns are mutually exclusive")
 	}
 
+	var sparseMinHole int64
+	if opts.SparseMinHole != "" {
+		if !opts.Sparse {
+			return errors.Fatal("--sparse-min-hole requires --sparse")
+		}
+		var err error
+		sparseMinHole, err = ui.ParseBytes(opts.SparseMinHole)
+		if err != nil {
+			return errors.Fatalf("invalid argument for --sparse-min-hole: %v", err)
+		}
+		if sparseMinHole <= 0 {
+			return errors.Fatal("--sparse-min-hole must be positive")
+		}
+	}
+
 	snapshotIDString := args[0]
 
 	debug.Log("restore %v to %v", snapshotIDString, opts.Target)
@@ -163,6 +180,7 @@ func runRestore(ctx context.Context, opts RestoreOptions, gopts GlobalOptions,
 
 	progress := restoreui.NewProgress(printer, calculateProgressInterval(!gopts.Quiet, gopts.JSON))
 	res := restorer.NewRestorer(repo, sn, opts.Sparse, progress)
+	res.SparseMinHole = int(sparseMinHole)
 
 	totalErrors := 0
 	res.Error = func(location string, err error) error {
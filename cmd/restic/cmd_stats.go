@@ -5,7 +5,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/restic/chunker"
 	"github.com/restic/restic/internal/crypto"
@@ -43,6 +45,9 @@ The modes are:
 * raw-data: Counts the size of blobs in the repository, regardless of
   how many files reference them.
 * blobs-per-file: A combination of files-by-contents and raw-data.
+* timeline: Processes the snapshots in chronological order and prints
+  the cumulative size of the unique blobs after each snapshot as one
+  JSON object per line.
 
 Refer to the online manual for more details about each mode.
 
@@ -70,7 +75,7 @@ var statsOptions StatsOptions
 func init() {
 	cmdRoot.AddCommand(cmdStats)
 	f := cmdStats.Flags()
-	f.StringVar(&statsOptions.countMode, "mode", countModeRestoreSize, "counting mode: restore-size (default), files-by-contents, blobs-per-file or raw-data")
+	f.StringVar(&statsOptions.countMode, "mode", countModeRestoreSize, "counting mode: restore-size (default), files-by-contents, blobs-per-file, raw-data or timeline")
 	initMultiSnapshotFilter(f, &statsOptions.SnapshotFilter, true)
 }
 
@@ -99,6 +104,24 @@ func runStats(ctx context.Context, opts StatsOptions, gopts GlobalOptions, args
 		return statsDebug(ctx, repo)
 	}
 
+	if opts.countMode == countModeTimeline {
+		var snapshots restic.Snapshots
+		for sn := range FindFilteredSnapshots(ctx, snapshotLister, repo, &opts.SnapshotFilter, args) {
+			snapshots = append(snapshots, sn)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		enc := json.NewEncoder(globalOptions.stdout)
+		return statsTimeline(ctx, repo, snapshots, func(entry statsTimelineEntry) error {
+			if err := enc.Encode(entry); err != nil {
+				return fmt.Errorf("encoding output: %v", err)
+			}
+			return nil
+		})
+	}
+
 	if !gopts.JSON {
 		Printf("scanning...\n")
 	}
@@ -293,6 +316,7 @@ func verifyStatsInput(opts StatsOptions) error {
 	case countModeUniqueFilesByContents:
 	case countModeBlobsPerFile:
 	case countModeRawData:
+	case countModeTimeline:
 	case countModeDebug:
 	default:
 		return fmt.Errorf("unknown counting mode: %s (use the -h flag to get a list of supported modes)", opts.countMode)
@@ -338,9 +362,73 @@ const (
 	countModeUniqueFilesByContents = "files-by-contents"
 	countModeBlobsPerFile          = "blobs-per-file"
 	countModeRawData               = "raw-data"
+	countModeTimeline              = "timeline"
 	countModeDebug                 = "debug"
 )
 
+// statsTimelineEntry describes the repository growth up to and including a
+// single snapshot.
+type statsTimelineEntry struct {
+	Time       time.Time  `json:"time"`
+	SnapshotID *restic.ID `json:"snapshot_id"`
+	// the size of all unique blobs referenced by this and earlier snapshots
+	CumulativeSize      uint64 `json:"cumulative_size"`
+	CumulativeBlobCount uint64 `json:"cumulative_blob_count"`
+	// the size of the blobs first referenced by this snapshot
+	AddedSize      uint64 `json:"added_size"`
+	AddedBlobCount uint64 `json:"added_blob_count"`
+}
+
+// statsTimeline processes the snapshots sorted by time and calls fn for each
+// of them with the cumulative size of the unique blobs referenced so far. The
+// size of a blob is its size in the repository, that is after compression and
+// encryption.
+func statsTimeline(ctx context.Context, repo restic.Repository, snapshots restic.Snapshots, fn func(statsTimelineEntry) error) error {
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		return snapshots[i].Time.Before(snapshots[j].Time)
+	})
+
+	seen := restic.NewBlobSet()
+	var entry statsTimelineEntry
+	for _, sn := range snapshots {
+		if sn.Tree == nil {
+			return fmt.Errorf("snapshot %s has nil tree", sn.ID().Str())
+		}
+
+		blobs := restic.NewBlobSet()
+		err := restic.FindUsedBlobs(ctx, repo, restic.IDs{*sn.Tree}, blobs, nil)
+		if err != nil {
+			return fmt.Errorf("error walking snapshot: %v", err)
+		}
+
+		entry.Time = sn.Time
+		entry.SnapshotID = sn.ID()
+		entry.AddedSize = 0
+		entry.AddedBlobCount = 0
+		for h := range blobs {
+			if seen.Has(h) {
+				continue
+			}
+			seen.Insert(h)
+
+			pbs := repo.Index().Lookup(h)
+			if len(pbs) == 0 {
+				return fmt.Errorf("blob %v not found", h)
+			}
+			entry.AddedSize += uint64(pbs[0].Length)
+			entry.AddedBlobCount++
+		}
+		entry.CumulativeSize += entry.AddedSize
+		entry.CumulativeBlobCount += entry.AddedBlobCount
+
+		if err := fn(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func statsDebug(ctx context.Context, repo restic.Repository) error {
 	Warnf("Collecting size statistics\n\n")
 	for _, t := range []restic.FileType{restic.KeyFile, restic.LockFile, restic.IndexFile, restic.PackFile} {
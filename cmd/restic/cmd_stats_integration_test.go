@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rtest "github.com/restic/restic/internal/test"
+)
+
+func testRunStats(t testing.TB, opts StatsOptions, gopts GlobalOptions) []byte {
+	buf, err := withCaptureStdout(func() error {
+		return runStats(context.TODO(), opts, gopts, nil)
+	})
+	rtest.OK(t, err)
+	return buf.Bytes()
+}
+
+func TestStatsTimeline(t *testing.T) {
+	env, cleanup := withTestEnvironment(t)
+	defer cleanup()
+
+	testRunInit(t, env.gopts)
+	env.gopts.JSON = true
+
+	datadir := filepath.Join(env.base, "data")
+	rtest.OK(t, os.MkdirAll(datadir, 0755))
+
+	// the first snapshot contains one file, the second one adds a file and
+	// the third one adds nothing
+	rtest.OK(t, appendRandomData(filepath.Join(datadir, "first"), 1024*1024))
+	testRunBackup(t, "", []string{datadir}, BackupOptions{}, env.gopts)
+	rtest.OK(t, appendRandomData(filepath.Join(datadir, "second"), 1024*1024))
+	testRunBackup(t, "", []string{datadir}, BackupOptions{}, env.gopts)
+	testRunBackup(t, "", []string{datadir}, BackupOptions{}, env.gopts)
+
+	out := testRunStats(t, StatsOptions{countMode: countModeTimeline}, env.gopts)
+
+	var entries []statsTimelineEntry
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		var entry statsTimelineEntry
+		rtest.OK(t, json.Unmarshal(sc.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	rtest.OK(t, sc.Err())
+	rtest.Equals(t, 3, len(entries))
+
+	for i := 1; i < len(entries); i++ {
+		rtest.Assert(t, !entries[i].Time.Before(entries[i-1].Time), "entries are not sorted by time")
+	}
+
+	// each file adds at least its size to the repository
+	rtest.Assert(t, entries[0].CumulativeSize >= 1024*1024, "unexpected size %d for first snapshot", entries[0].CumulativeSize)
+	rtest.Assert(t, entries[1].AddedSize >= 1024*1024, "unexpected growth %d for second snapshot", entries[1].AddedSize)
+	rtest.Equals(t, entries[0].CumulativeSize+entries[1].AddedSize, entries[1].CumulativeSize)
+	// the third snapshot only references existing data, but the tree
+	// metadata may have changed, e.g. due to updated access times
+	rtest.Assert(t, entries[2].AddedSize < 64*1024, "unexpected growth %d for third snapshot", entries[2].AddedSize)
+	rtest.Equals(t, entries[1].CumulativeSize+entries[2].AddedSize, entries[2].CumulativeSize)
+
+	// the final size must match the raw-data statistics across all snapshots
+	var stats statsContainer
+	out = testRunStats(t, StatsOptions{countMode: countModeRawData}, env.gopts)
+	rtest.OK(t, json.Unmarshal(out, &stats))
+	rtest.Equals(t, stats.TotalSize, entries[2].CumulativeSize)
+	rtest.Equals(t, stats.TotalBlobCount, entries[2].CumulativeBlobCount)
+}
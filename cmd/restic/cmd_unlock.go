@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"time"
 
+	"github.com/restic/restic/internal/errors"
 	"github.com/restic/restic/internal/restic"
 	"github.com/spf13/cobra"
 )
@@ -13,6 +15,11 @@ var unlockCmd = &cobra.Command{
 	Long: `
 The "unlock" command removes stale locks that have been created by other restic processes.
 
+With "--stale-only", only locks which are known to be stale are removed: locks
+of processes on this host which are no longer running, and locks from other
+hosts which were not refreshed within the duration given by "--stale-age".
+Locks which might still be in use are reported, but never removed.
+
 EXIT STATUS
 ===========
 
@@ -27,6 +34,8 @@ Exit status is 0 if the command was successful, and non-zero if there was any er
 // UnlockOptions collects all options for the unlock command.
 type UnlockOptions struct {
 	RemoveAll bool
+	StaleOnly bool
+	StaleAge  time.Duration
 }
 
 var unlockOptions UnlockOptions
@@ -35,9 +44,18 @@ func init() {
 	cmdRoot.AddCommand(unlockCmd)
 
 	unlockCmd.Flags().BoolVar(&unlockOptions.RemoveAll, "remove-all", false, "remove all locks, even non-stale ones")
+	unlockCmd.Flags().BoolVar(&unlockOptions.StaleOnly, "stale-only", false, "only remove locks which are known to be stale and report ambiguous ones")
+	unlockCmd.Flags().DurationVar(&unlockOptions.StaleAge, "stale-age", restic.StaleLockTimeout, "with --stale-only, remove locks from other hosts which were not refreshed for `duration`")
 }
 
 func runUnlock(ctx context.Context, opts UnlockOptions, gopts GlobalOptions) error {
+	if opts.RemoveAll && opts.StaleOnly {
+		return errors.Fatal("--remove-all and --stale-only cannot be used together")
+	}
+	if opts.StaleOnly && opts.StaleAge <= 0 {
+		return errors.Fatal("--stale-age must be positive")
+	}
+
 	repo, err := OpenRepository(ctx, gopts)
 	if err != nil {
 		return err
@@ -47,6 +65,13 @@ func runUnlock(ctx context.Context, opts UnlockOptions, gopts GlobalOptions) err
 	if opts.RemoveAll {
 		fn = restic.RemoveAllLocks
 	}
+	if opts.StaleOnly {
+		fn = func(ctx context.Context, repo restic.Repository) (uint, error) {
+			return restic.RemoveProvenStaleLocks(ctx, repo, opts.StaleAge, func(id restic.ID, _ *restic.Lock, reason string) {
+				Printf("not removing lock %v: %v\n", id.Str(), reason)
+			})
+		}
+	}
 
 	processed, err := fn(ctx, repo)
 	if err != nil {
@@ -9,6 +9,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/restic/restic/internal/debug"
 	"github.com/restic/restic/internal/errors"
@@ -204,6 +205,135 @@ func isDirExcludedByFile(dir, tagFilename, header string) bool {
 	return true
 }
 
+// resticIgnoreFilename is the name of the per-directory ignore files which
+// are honored when --resticignore is specified.
+const resticIgnoreFilename = ".resticignore"
+
+// ignorePattern is a single line of an ignore file.
+type ignorePattern struct {
+	pattern []filter.Pattern
+	negated bool
+}
+
+// ignoreFileCache caches the parsed ignore files per directory.
+type ignoreFileCache struct {
+	m   map[string][]ignorePattern
+	mtx sync.Mutex
+}
+
+// rejectByIgnoreFiles returns a RejectByNameFunc which rejects files that are
+// matched by a pattern in an ignore file called filename in one of their
+// parent directories. The patterns in an ignore file are matched against the
+// path relative to the directory which contains it, thus they apply to that
+// directory and everything below. Patterns starting with "/" are anchored at
+// that directory. Like for .gitignore files, the last matching pattern wins,
+// patterns from ignore files in deeper directories take precedence and a
+// pattern prefixed with "!" includes a previously ignored file again. A file
+// excluded by other means, for example by --exclude, cannot be included again.
+func rejectByIgnoreFiles(filename string) RejectByNameFunc {
+	cache := &ignoreFileCache{m: make(map[string][]ignorePattern)}
+	return func(item string) bool {
+		item = filepath.Clean(item)
+
+		// collect all parent directories, starting at the root
+		var dirs []string
+		for dir := filepath.Dir(item); ; dir = filepath.Dir(dir) {
+			dirs = append(dirs, dir)
+			if filepath.Dir(dir) == dir {
+				break
+			}
+		}
+
+		rejected := false
+		for i := len(dirs) - 1; i >= 0; i-- {
+			patterns := cache.load(dirs[i], filename)
+			if len(patterns) == 0 {
+				continue
+			}
+
+			rel, err := filepath.Rel(dirs[i], item)
+			if err != nil {
+				continue
+			}
+			rel = string(filepath.Separator) + rel
+
+			for _, p := range patterns {
+				matched, err := filter.List(p.pattern, rel)
+				if err != nil {
+					Warnf("error for pattern in %v: %v", filepath.Join(dirs[i], filename), err)
+					continue
+				}
+				if matched {
+					rejected = !p.negated
+				}
+			}
+		}
+
+		if rejected {
+			debug.Log("path %q excluded by an ignore file", item)
+		}
+		return rejected
+	}
+}
+
+// load returns the patterns from the ignore file in dir, the file is only read
+// once.
+func (c *ignoreFileCache) load(dir, filename string) []ignorePattern {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if patterns, ok := c.m[dir]; ok {
+		return patterns
+	}
+
+	patterns, err := readIgnoreFile(filepath.Join(dir, filename))
+	if err != nil {
+		Warnf("could not read ignore file: %v\n", err)
+	}
+	c.m[dir] = patterns
+	return patterns
+}
+
+// readIgnoreFile reads the patterns from an ignore file. Empty lines and
+// comments are skipped. A missing file yields no patterns and no error. In
+// contrast to --exclude-file, environment variables are not expanded as
+// ignore files may be controlled by other users.
+func readIgnoreFile(filename string) ([]ignorePattern, error) {
+	data, err := textfile.Read(filename)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var patterns []ignorePattern
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		negated := strings.HasPrefix(line, "!")
+		if negated {
+			line = line[1:]
+		}
+		if err := filter.ValidatePatterns([]string{line}); err != nil {
+			return nil, fmt.Errorf("%v: %w", filename, err)
+		}
+
+		patterns = append(patterns, ignorePattern{
+			pattern: filter.ParsePatterns([]string{line}),
+			negated: negated,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%v: %w", filename, err)
+	}
+	return patterns, nil
+}
+
 // DeviceMap is used to track allowed source devices for backup. This is used to
 // check for crossing mount points during backup (for --one-file-system). It
 // maps the name of a source path to its device ID.
@@ -385,6 +515,36 @@ func rejectBySize(maxSizeStr string) (RejectFunc, error) {
 	}, nil
 }
 
+// rejectByMtime returns a RejectFunc which rejects files that were modified
+// within the given duration before the RejectFunc was created, or afterwards.
+// This prevents saving files which are still being written to, a later backup
+// will pick them up once they have settled. Directories are never rejected as
+// their modification time changes with every file created within.
+func rejectByMtime(windowStr string) (RejectFunc, error) {
+	window, err := time.ParseDuration(windowStr)
+	if err != nil {
+		return nil, errors.Fatalf("invalid duration %q: %v", windowStr, err)
+	}
+	if window <= 0 {
+		return nil, errors.Fatalf("duration %q must be positive", windowStr)
+	}
+
+	threshold := time.Now().Add(-window)
+	return func(item string, fi os.FileInfo) bool {
+		// directory will be ignored
+		if fi.IsDir() {
+			return false
+		}
+
+		if fi.ModTime().After(threshold) {
+			debug.Log("file %s was modified too recently: %v", item, fi.ModTime())
+			return true
+		}
+
+		return false
+	}, nil
+}
+
 // readExcludePatternsFromFiles reads all exclude files and returns the list of
 // exclude patterns. For each line, leading and trailing white space is removed
 // and comment lines are ignored. For each remaining pattern, environment
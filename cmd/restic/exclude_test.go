@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/restic/restic/internal/test"
 )
@@ -187,6 +188,76 @@ func TestMultipleIsExcludedByFile(t *testing.T) {
 	}
 }
 
+func TestRejectByIgnoreFiles(t *testing.T) {
+	tempDir := test.TempDir(t)
+
+	files := []struct {
+		path string
+		incl bool
+	}{
+		{".resticignore", true},
+		{"a.log", false},
+		{"keep.log", true},
+		{"foo.tmp", true},
+		{"build/x", false},
+		{"tmp/x", false},
+
+		// src/.resticignore overrides the patterns from the parent directory
+		{"src/.resticignore", true},
+		{"src/debug.log", true},
+		{"src/other.log", false},
+		{"src/build/y", true},
+		{"src/tmp/x", false},
+		{"src/sub/foo.tmp", false},
+
+		// src/sub/.resticignore overrides src/.resticignore again
+		{"src/sub/.resticignore", true},
+		{"src/sub/debug.log", false},
+		{"src/sub/bar", true},
+	}
+	ignoreFiles := map[string]string{
+		".resticignore":         "# comment\n*.log\n!keep.log\n/build\ntmp\n",
+		"src/.resticignore":     "!debug.log\n*.tmp\n",
+		"src/sub/.resticignore": "debug.log\n",
+	}
+
+	var errs []error
+	for _, f := range files {
+		p := filepath.Join(tempDir, filepath.FromSlash(f.path))
+		data := ignoreFiles[f.path]
+		if data == "" {
+			data = f.path
+		}
+		errs = append(errs, os.MkdirAll(filepath.Dir(p), 0700))
+		errs = append(errs, os.WriteFile(p, []byte(data), 0600))
+	}
+	test.OKs(t, errs)
+
+	reject := rejectByIgnoreFiles(resticIgnoreFilename)
+
+	// mock the archiver, which does not descend into rejected directories
+	m := make(map[string]bool)
+	walk := func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		excluded := reject(p)
+		m[p] = !excluded
+		if excluded && fi.IsDir() {
+			return filepath.SkipDir
+		}
+		return nil
+	}
+	test.OK(t, filepath.Walk(tempDir, walk))
+
+	for _, f := range files {
+		p := filepath.Join(tempDir, filepath.FromSlash(f.path))
+		if m[p] != f.incl {
+			t.Errorf("inclusion status of %s is wrong: want %v, got %v", f.path, f.incl, m[p])
+		}
+	}
+}
+
 // TestIsExcludedByFileSize is for testing the instance of
 // --exclude-larger-than parameters
 func TestIsExcludedByFileSize(t *testing.T) {
@@ -267,6 +338,73 @@ func TestIsExcludedByFileSize(t *testing.T) {
 	}
 }
 
+func TestIsExcludedByMtime(t *testing.T) {
+	tempDir := test.TempDir(t)
+
+	old := time.Now().Add(-time.Hour)
+	files := []struct {
+		path    string
+		settled bool
+		incl    bool
+	}{
+		{"settled", true, true},
+		{"recent", false, false},
+		{"dir/settled", true, true},
+		{"dir/recent", false, false},
+	}
+	var errs []error
+	for _, f := range files {
+		p := filepath.Join(tempDir, filepath.FromSlash(f.path))
+		errs = append(errs, os.MkdirAll(filepath.Dir(p), 0700))
+		errs = append(errs, os.WriteFile(p, []byte(f.path), 0600))
+		if f.settled {
+			errs = append(errs, os.Chtimes(p, old, old))
+		}
+	}
+	test.OKs(t, errs)
+
+	mtimeExclude, err := rejectByMtime("30s")
+	test.OK(t, err)
+
+	m := make(map[string]bool)
+	walk := func(p string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		excluded := mtimeExclude(p, fi)
+		t.Logf("%q: dir:%t; mtime:%v; excluded:%v", p, fi.IsDir(), fi.ModTime(), excluded)
+		m[p] = !excluded
+		return nil
+	}
+	test.OK(t, filepath.Walk(tempDir, walk))
+
+	// directories are never excluded
+	test.Assert(t, m[filepath.Join(tempDir, "dir")], "directory was excluded")
+	for _, f := range files {
+		p := filepath.Join(tempDir, filepath.FromSlash(f.path))
+		if m[p] != f.incl {
+			t.Errorf("inclusion status of %s is wrong: want %v, got %v", f.path, f.incl, m[p])
+		}
+	}
+
+	// a later run picks up the files once they have settled
+	laterExclude, err := rejectByMtime("30s")
+	test.OK(t, err)
+	for _, f := range files {
+		p := filepath.Join(tempDir, filepath.FromSlash(f.path))
+		test.OK(t, os.Chtimes(p, old, old))
+		fi, err := os.Lstat(p)
+		test.OK(t, err)
+		test.Assert(t, !laterExclude(p, fi), "settled file %v was excluded", f.path)
+	}
+
+	for _, invalid := range []string{"", "foo", "0s", "-5s"} {
+		_, err := rejectByMtime(invalid)
+		test.Assert(t, err != nil, "expected error for duration %q", invalid)
+	}
+}
+
 func TestDeviceMap(t *testing.T) {
 	deviceMap := DeviceMap{
 		filepath.FromSlash("/"):          1,
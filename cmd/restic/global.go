@@ -20,6 +20,7 @@ import (
 	"github.com/restic/restic/internal/backend/local"
 	"github.com/restic/restic/internal/backend/location"
 	"github.com/restic/restic/internal/backend/logger"
+	"github.com/restic/restic/internal/backend/presigned"
 	"github.com/restic/restic/internal/backend/rclone"
 	"github.com/restic/restic/internal/backend/rest"
 	"github.com/restic/restic/internal/backend/retry"
@@ -67,6 +68,7 @@ type GlobalOptions struct {
 	CleanupCache    bool
 	Compression     repository.CompressionMode
 	PackSize        uint
+	AutoPackSize    bool
 	NoExtraVerify   bool
 
 	backend.TransportOptions
@@ -102,6 +104,7 @@ func init() {
 	backends.Register(b2.NewFactory())
 	backends.Register(gs.NewFactory())
 	backends.Register(local.NewFactory())
+	backends.Register(presigned.NewFactory())
 	backends.Register(rclone.NewFactory())
 	backends.Register(rest.NewFactory())
 	backends.Register(s3.NewFactory())
@@ -131,7 +134,7 @@ func init() {
 	f.BoolVar(&globalOptions.NoExtraVerify, "no-extra-verify", false, "skip additional verification of data before upload (see documentation)")
 	f.IntVar(&globalOptions.Limits.UploadKb, "limit-upload", 0, "limits uploads to a maximum `rate` in KiB/s. (default: unlimited)")
 	f.IntVar(&globalOptions.Limits.DownloadKb, "limit-download", 0, "limits downloads to a maximum `rate` in KiB/s. (default: unlimited)")
-	f.UintVar(&globalOptions.PackSize, "pack-size", 0, "set target pack `size` in MiB, created pack files may be larger (default: $RESTIC_PACK_SIZE)")
+	f.Var(&packSizeOption{&globalOptions}, "pack-size", "set target pack `size` in MiB, or auto to scale it with the repository size, created pack files may be larger (default: $RESTIC_PACK_SIZE)")
 	f.StringSliceVarP(&globalOptions.Options, "option", "o", []string{}, "set extended option (`key=value`, can be specified multiple times)")
 	// Use our "generate" command instead of the cobra provided "completion" command
 	cmdRoot.CompletionOptions.DisableDefaultCmd = true
@@ -151,8 +154,42 @@ func init() {
 		_ = globalOptions.Compression.Set(comp)
 	}
 	// parse target pack size from env, on error the default value will be used
-	targetPackSize, _ := strconv.ParseUint(os.Getenv("RESTIC_PACK_SIZE"), 10, 32)
-	globalOptions.PackSize = uint(targetPackSize)
+	_ = (&packSizeOption{&globalOptions}).Set(os.Getenv("RESTIC_PACK_SIZE"))
+}
+
+// packSizeOption implements the --pack-size option, which is either a size in
+// MiB or "auto".
+type packSizeOption struct {
+	opts *GlobalOptions
+}
+
+func (o *packSizeOption) Set(s string) error {
+	if s == "auto" {
+		o.opts.PackSize = 0
+		o.opts.AutoPackSize = true
+		return nil
+	}
+
+	size, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		o.opts.PackSize = 0
+		o.opts.AutoPackSize = false
+		return fmt.Errorf("invalid pack size %q, must be a size in MiB or auto", s)
+	}
+	o.opts.PackSize = uint(size)
+	o.opts.AutoPackSize = false
+	return nil
+}
+
+func (o *packSizeOption) String() string {
+	if o.opts.AutoPackSize {
+		return "auto"
+	}
+	return strconv.FormatUint(uint64(o.opts.PackSize), 10)
+}
+
+func (o *packSizeOption) Type() string {
+	return "size"
 }
 
 func stdinIsTerminal() bool {
@@ -434,6 +471,7 @@ func OpenRepository(ctx context.Context, opts GlobalOptions) (*repository.Reposi
 	s, err := repository.New(be, repository.Options{
 		Compression:   opts.Compression,
 		PackSize:      opts.PackSize * 1024 * 1024,
+		AutoPackSize:  opts.AutoPackSize,
 		NoExtraVerify: opts.NoExtraVerify,
 	})
 	if err != nil {
@@ -104,6 +104,11 @@ type Archiver struct {
 	// default.
 	WithAtime bool
 
+	// WithContentHash configures if the SHA-256 hash of the whole content
+	// should be stored for each file. For unchanged files, the hash is taken
+	// over from the parent snapshot.
+	WithContentHash bool
+
 	// Flags controlling change detection. See doc/040_backup.rst for details.
 	ChangeIgnoreFlags uint
 }
@@ -452,6 +457,9 @@ func (arch *Archiver) save(ctx context.Context, snPath, target string, previous
 
 				// copy list of blobs
 				node.Content = previous.Content
+				if arch.WithContentHash {
+					node.ContentHash = previous.ContentHash
+				}
 
 				fn = newFutureNodeWithResult(futureNodeResult{
 					snPath: snPath,
@@ -751,6 +759,7 @@ func resolveRelativeTargets(filesys fs.FS, targets []string) ([]string, error) {
 // SnapshotOptions collect attributes for a new snapshot.
 type SnapshotOptions struct {
 	Tags           restic.TagList
+	Annotations    map[string]string
 	Hostname       string
 	Excludes       []string
 	BackupStart    time.Time
@@ -789,6 +798,7 @@ func (arch *Archiver) runWorkers(ctx context.Context, wg *errgroup.Group) {
 		arch.Repo.Config().ChunkerPolynomial,
 		arch.Options.ReadConcurrency, arch.Options.SaveBlobConcurrency)
 	arch.fileSaver.CompleteBlob = arch.CompleteBlob
+	arch.fileSaver.ContentHash = arch.WithContentHash
 	arch.fileSaver.NodeFromFileInfo = arch.nodeFromFileInfo
 
 	arch.treeSaver = NewTreeSaver(ctx, wg, arch.Options.SaveTreeConcurrency, arch.blobSaver.Save, arch.Error)
@@ -877,6 +887,7 @@ func (arch *Archiver) Snapshot(ctx context.Context, targets []string, opts Snaps
 
 	sn.ProgramVersion = opts.ProgramVersion
 	sn.Excludes = opts.Excludes
+	sn.Annotations = opts.Annotations
 	if opts.ParentSnapshot != nil {
 		sn.Parent = opts.ParentSnapshot.ID()
 	}
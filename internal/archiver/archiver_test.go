@@ -2338,3 +2338,34 @@ func TestRacyFileSwap(t *testing.T) {
 		t.Errorf("Save() excluded the node, that's unexpected")
 	}
 }
+
+func TestArchiverContentHash(t *testing.T) {
+	repo := repository.TestRepository(t)
+	data := rtest.Random(42, 5*1024*1024+123)
+
+	for _, withHash := range []bool{false, true} {
+		readerFs := &fs.Reader{
+			ModTime:    time.Now(),
+			Mode:       0644,
+			Name:       "file",
+			ReadCloser: io.NopCloser(bytes.NewReader(data)),
+		}
+
+		arch := New(repo, readerFs, Options{})
+		arch.WithContentHash = withHash
+		sn, _, _, err := arch.Snapshot(context.TODO(), []string{"file"}, SnapshotOptions{Time: time.Now()})
+		rtest.OK(t, err)
+
+		tree, err := restic.LoadTree(context.TODO(), repo, *sn.Tree)
+		rtest.OK(t, err)
+		node := tree.Find("file")
+		rtest.Assert(t, node != nil, "file node not found")
+
+		if withHash {
+			rtest.Assert(t, node.ContentHash != nil, "content hash missing")
+			rtest.Equals(t, restic.Hash(data), *node.ContentHash)
+		} else {
+			rtest.Assert(t, node.ContentHash == nil, "unexpected content hash %v", node.ContentHash)
+		}
+	}
+}
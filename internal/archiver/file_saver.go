@@ -3,10 +3,12 @@ package archiver
 import (
 	"context"
 	"fmt"
+	"hash"
 	"io"
 	"os"
 	"sync"
 
+	"github.com/minio/sha256-simd"
 	"github.com/restic/chunker"
 	"github.com/restic/restic/internal/debug"
 	"github.com/restic/restic/internal/errors"
@@ -29,6 +31,10 @@ type FileSaver struct {
 
 	CompleteBlob func(bytes uint64)
 
+	// ContentHash configures whether the SHA-256 hash of the whole file
+	// content is stored in the node.
+	ContentHash bool
+
 	NodeFromFileInfo func(snPath, filename string, fi os.FileInfo, ignoreXattrListError bool) (*restic.Node, error)
 }
 
@@ -172,6 +178,11 @@ func (s *FileSaver) saveFile(ctx context.Context, chnker *chunker.Chunker, snPat
 	// reuse the chunker
 	chnker.Reset(f, s.pol)
 
+	var hasher hash.Hash
+	if s.ContentHash {
+		hasher = sha256.New()
+	}
+
 	node.Content = []restic.ID{}
 	node.Size = 0
 	var idx int
@@ -191,6 +202,10 @@ func (s *FileSaver) saveFile(ctx context.Context, chnker *chunker.Chunker, snPat
 			completeError(err)
 			return
 		}
+		if hasher != nil {
+			// the data must be hashed before passing the buffer to saveBlob
+			_, _ = hasher.Write(chunk.Data)
+		}
 		// test if the context has been cancelled, return the error
 		if ctx.Err() != nil {
 			_ = f.Close()
@@ -236,6 +251,11 @@ func (s *FileSaver) saveFile(ctx context.Context, chnker *chunker.Chunker, snPat
 		return
 	}
 
+	if hasher != nil {
+		id := restic.IDFromHash(hasher.Sum(nil))
+		node.ContentHash = &id
+	}
+
 	fnr.node = node
 	lock.Lock()
 	// require one additional completeFuture() call to ensure that the future only completes
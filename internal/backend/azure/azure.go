@@ -14,6 +14,7 @@ import (
 
 	"github.com/restic/restic/internal/backend"
 	"github.com/restic/restic/internal/backend/layout"
+	"github.com/restic/restic/internal/backend/limiter"
 	"github.com/restic/restic/internal/backend/location"
 	"github.com/restic/restic/internal/backend/util"
 	"github.com/restic/restic/internal/debug"
@@ -36,6 +37,7 @@ type Backend struct {
 	connections  uint
 	prefix       string
 	listMaxItems int
+	listLimiter  *limiter.ListLimiter
 	layout.Layout
 }
 
@@ -123,6 +125,7 @@ func open(cfg Config, rt http.RoundTripper) (*Backend, error) {
 			Join: path.Join,
 		},
 		listMaxItems: defaultListMaxItems,
+		listLimiter:  limiter.NewListLimiter(cfg.ListRateLimit),
 	}
 
 	return be, nil
@@ -367,6 +370,11 @@ func (be *Backend) List(ctx context.Context, t backend.FileType, fn func(backend
 	lister := be.container.NewListBlobsFlatPager(opts)
 
 	for lister.More() {
+		err := be.listLimiter.Wait(ctx)
+		if err != nil {
+			return err
+		}
+
 		resp, err := lister.NextPage(ctx)
 
 		if err != nil {
@@ -385,6 +393,9 @@ func (be *Backend) List(ctx context.Context, t backend.FileType, fn func(backend
 				Name: path.Base(m),
 				Size: *item.Properties.ContentLength,
 			}
+			if item.Properties.LastModified != nil {
+				fi.ModTime = *item.Properties.LastModified
+			}
 
 			if ctx.Err() != nil {
 				return ctx.Err()
@@ -20,7 +20,8 @@ type Config struct {
 	Container      string
 	Prefix         string
 
-	Connections uint `option:"connections" help:"set a limit for the number of concurrent connections (default: 5)"`
+	Connections   uint `option:"connections" help:"set a limit for the number of concurrent connections (default: 5)"`
+	ListRateLimit uint `option:"list-rate-limit" help:"set a limit for the number of list requests per second (default: unlimited)"`
 }
 
 // NewConfig returns a new Config with the default values filled in.
@@ -300,8 +300,9 @@ func (be *b2Backend) List(ctx context.Context, t backend.FileType, fn func(backe
 		}
 
 		fi := backend.FileInfo{
-			Name: path.Base(obj.Name()),
-			Size: attrs.Size,
+			Name:    path.Base(obj.Name()),
+			Size:    attrs.Size,
+			ModTime: attrs.UploadTimestamp,
 		}
 
 		if err := fn(fi); err != nil {
@@ -4,6 +4,7 @@ import (
 	"context"
 	"hash"
 	"io"
+	"time"
 )
 
 // Backend is used to store and access data.
@@ -104,6 +105,9 @@ type FreezeBackend interface {
 type FileInfo struct {
 	Size int64
 	Name string
+	// ModTime is the time the file was last modified. It is only set by List
+	// and may be zero if the backend does not report it.
+	ModTime time.Time
 }
 
 // ApplyEnvironmenter fills in a backend configuration from the environment
@@ -18,8 +18,9 @@ type Config struct {
 	Bucket    string
 	Prefix    string
 
-	Connections uint   `option:"connections" help:"set a limit for the number of concurrent connections (default: 5)"`
-	Region      string `option:"region" help:"region to create the bucket in (default: us)"`
+	Connections   uint   `option:"connections" help:"set a limit for the number of concurrent connections (default: 5)"`
+	Region        string `option:"region" help:"region to create the bucket in (default: us)"`
+	ListRateLimit uint   `option:"list-rate-limit" help:"set a limit for the number of list requests per second (default: unlimited)"`
 }
 
 // NewConfig returns a new Config with the default values filled in.
@@ -15,6 +15,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/restic/restic/internal/backend"
 	"github.com/restic/restic/internal/backend/layout"
+	"github.com/restic/restic/internal/backend/limiter"
 	"github.com/restic/restic/internal/backend/location"
 	"github.com/restic/restic/internal/backend/util"
 	"github.com/restic/restic/internal/debug"
@@ -42,6 +43,7 @@ type Backend struct {
 	bucket       *storage.BucketHandle
 	prefix       string
 	listMaxItems int
+	listLimiter  *limiter.ListLimiter
 	layout.Layout
 }
 
@@ -116,6 +118,7 @@ func open(cfg Config, rt http.RoundTripper) (*Backend, error) {
 			Join: path.Join,
 		},
 		listMaxItems: defaultListMaxItems,
+		listLimiter:  limiter.NewListLimiter(cfg.ListRateLimit),
 	}
 
 	return be, nil
@@ -316,32 +319,44 @@ func (be *Backend) List(ctx context.Context, t backend.FileType, fn func(backend
 	defer cancel()
 
 	itr := be.bucket.Objects(ctx, &storage.Query{Prefix: prefix})
+	pager := iterator.NewPager(itr, be.listMaxItems, "")
 
 	for {
-		attrs, err := itr.Next()
-		if err == iterator.Done {
-			break
-		}
+		err := be.listLimiter.Wait(ctx)
 		if err != nil {
 			return err
 		}
-		m := strings.TrimPrefix(attrs.Name, prefix)
-		if m == "" {
-			continue
-		}
-
-		fi := backend.FileInfo{
-			Name: path.Base(m),
-			Size: int64(attrs.Size),
-		}
 
-		err = fn(fi)
+		var page []*storage.ObjectAttrs
+		token, err := pager.NextPage(&page)
 		if err != nil {
 			return err
 		}
 
-		if ctx.Err() != nil {
-			return ctx.Err()
+		for _, attrs := range page {
+			m := strings.TrimPrefix(attrs.Name, prefix)
+			if m == "" {
+				continue
+			}
+
+			fi := backend.FileInfo{
+				Name:    path.Base(m),
+				Size:    int64(attrs.Size),
+				ModTime: attrs.Updated,
+			}
+
+			err = fn(fi)
+			if err != nil {
+				return err
+			}
+
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+
+		if token == "" {
+			break
 		}
 	}
 
@@ -0,0 +1,52 @@
+package limiter
+
+import (
+	"context"
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// ListLimiter limits the rate at which the pages of a List operation are
+// requested. This avoids running into the request rate limits of object
+// storage providers when listing large buckets. A nil *ListLimiter does not
+// limit the rate.
+type ListLimiter struct {
+	bucket *rate.Limiter
+}
+
+// NewListLimiter returns a ListLimiter which allows at most pagesPerSecond
+// page requests per second. For zero, nil is returned.
+func NewListLimiter(pagesPerSecond uint) *ListLimiter {
+	if pagesPerSecond == 0 {
+		return nil
+	}
+	return &ListLimiter{
+		bucket: rate.NewLimiter(rate.Limit(pagesPerSecond), 1),
+	}
+}
+
+// Wait blocks until the next page may be requested.
+func (l *ListLimiter) Wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	return l.bucket.Wait(ctx)
+}
+
+// Transport returns an HTTP transport which calls Wait before each request for
+// which isList returns true. This is intended for client libraries which
+// implement the pagination loop internally.
+func (l *ListLimiter) Transport(rt http.RoundTripper, isList func(*http.Request) bool) http.RoundTripper {
+	if l == nil {
+		return rt
+	}
+	return roundTripper(func(req *http.Request) (*http.Response, error) {
+		if isList(req) {
+			if err := l.Wait(req.Context()); err != nil {
+				return nil, err
+			}
+		}
+		return rt.RoundTrip(req)
+	})
+}
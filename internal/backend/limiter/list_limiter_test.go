@@ -0,0 +1,66 @@
+package limiter
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/restic/restic/internal/test"
+)
+
+func TestListLimiterNil(t *testing.T) {
+	l := NewListLimiter(0)
+	test.Assert(t, l == nil, "expected nil limiter")
+	test.OK(t, l.Wait(context.TODO()))
+
+	rt := roundTripper(func(*http.Request) (*http.Response, error) { return nil, nil })
+	test.Assert(t, l.Transport(rt, nil) != nil, "expected transport")
+}
+
+func TestListLimiterRate(t *testing.T) {
+	const pagesPerSecond = 20
+	l := NewListLimiter(pagesPerSecond)
+
+	start := time.Now()
+	for i := 0; i < 11; i++ {
+		test.OK(t, l.Wait(context.TODO()))
+	}
+	// the first page is not delayed
+	elapsed := time.Since(start)
+	test.Assert(t, elapsed >= 450*time.Millisecond, "11 pages took only %v", elapsed)
+}
+
+func TestListLimiterTransport(t *testing.T) {
+	l := NewListLimiter(1)
+	var requests int
+	rt := l.Transport(roundTripper(func(*http.Request) (*http.Response, error) {
+		requests++
+		return &http.Response{}, nil
+	}), func(req *http.Request) bool {
+		return req.URL.Query().Has("list")
+	})
+
+	// non-list requests are not limited
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		req, err := http.NewRequest(http.MethodGet, "http://localhost/file", nil)
+		test.OK(t, err)
+		_, err = rt.RoundTrip(req)
+		test.OK(t, err)
+	}
+	test.Assert(t, time.Since(start) < 500*time.Millisecond, "non-list requests were limited")
+
+	// the first list request consumes the only token, thus the second one
+	// must wait until the context is cancelled
+	req, err := http.NewRequest(http.MethodGet, "http://localhost/?list", nil)
+	test.OK(t, err)
+	_, err = rt.RoundTrip(req)
+	test.OK(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = rt.RoundTrip(req.WithContext(ctx))
+	test.Assert(t, err != nil, "expected error for limited list request")
+	test.Equals(t, 6, requests)
+}
@@ -341,8 +341,9 @@ func visitFiles(ctx context.Context, dir string, fn func(backend.FileInfo) error
 		}
 
 		err := fn(backend.FileInfo{
-			Name: fi.Name(),
-			Size: fi.Size(),
+			Name:    fi.Name(),
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
 		})
 		if err != nil {
 			return err
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"context"
+	"fmt"
 	"io"
 
 	"github.com/restic/restic/internal/backend"
@@ -19,7 +20,46 @@ func New(be backend.Backend) *Backend {
 	return &Backend{Backend: be}
 }
 
+// traceError annotates an error with the trace id of the operation which
+// returned it.
+type traceError struct {
+	traceID string
+	err     error
+}
+
+func (e *traceError) Error() string {
+	return fmt.Sprintf("%v (trace id %v)", e.err, e.traceID)
+}
+
+func (e *traceError) Unwrap() error { return e.err }
+
+// Cause returns the underlying error for errors.Cause.
+func (e *traceError) Cause() error { return e.err }
+
+// annotate adds the trace id from ctx to err, if both are set.
+func annotate(ctx context.Context, err error) error {
+	id := backend.TraceID(ctx)
+	if err == nil || id == "" {
+		return err
+	}
+	return &traceError{traceID: id, err: err}
+}
+
+// traceInfo returns a suffix for log messages which contains the trace id
+// from ctx.
+func traceInfo(ctx context.Context) string {
+	id := backend.TraceID(ctx)
+	if id == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [trace id %v]", id)
+}
+
 func (be *Backend) IsNotExist(err error) bool {
+	// strip the annotation, not all backends unwrap errors
+	if terr, ok := err.(*traceError); ok {
+		err = terr.err
+	}
 	isNotExist := be.Backend.IsNotExist(err)
 	debug.Log("IsNotExist(%T, %#v, %v)", err, err, isNotExist)
 	return isNotExist
@@ -27,46 +67,46 @@ func (be *Backend) IsNotExist(err error) bool {
 
 // Save adds new Data to the backend.
 func (be *Backend) Save(ctx context.Context, h backend.Handle, rd backend.RewindReader) error {
-	debug.Log("Save(%v, %v)", h, rd.Length())
+	debug.Log("Save(%v, %v)%v", h, rd.Length(), traceInfo(ctx))
 	err := be.Backend.Save(ctx, h, rd)
 	debug.Log("  save err %v", err)
-	return err
+	return annotate(ctx, err)
 }
 
 // Remove deletes a file from the backend.
 func (be *Backend) Remove(ctx context.Context, h backend.Handle) error {
-	debug.Log("Remove(%v)", h)
+	debug.Log("Remove(%v)%v", h, traceInfo(ctx))
 	err := be.Backend.Remove(ctx, h)
 	debug.Log("  remove err %v", err)
-	return err
+	return annotate(ctx, err)
 }
 
 func (be *Backend) Load(ctx context.Context, h backend.Handle, length int, offset int64, fn func(io.Reader) error) error {
-	debug.Log("Load(%v, length %v, offset %v)", h, length, offset)
+	debug.Log("Load(%v, length %v, offset %v)%v", h, length, offset, traceInfo(ctx))
 	err := be.Backend.Load(ctx, h, length, offset, fn)
 	debug.Log("  load err %v", err)
-	return err
+	return annotate(ctx, err)
 }
 
 func (be *Backend) Stat(ctx context.Context, h backend.Handle) (backend.FileInfo, error) {
-	debug.Log("Stat(%v)", h)
+	debug.Log("Stat(%v)%v", h, traceInfo(ctx))
 	fi, err := be.Backend.Stat(ctx, h)
 	debug.Log("  stat err %v", err)
-	return fi, err
+	return fi, annotate(ctx, err)
 }
 
 func (be *Backend) List(ctx context.Context, t backend.FileType, fn func(backend.FileInfo) error) error {
-	debug.Log("List(%v)", t)
+	debug.Log("List(%v)%v", t, traceInfo(ctx))
 	err := be.Backend.List(ctx, t, fn)
 	debug.Log("  list err %v", err)
-	return err
+	return annotate(ctx, err)
 }
 
 func (be *Backend) Delete(ctx context.Context) error {
-	debug.Log("Delete()")
+	debug.Log("Delete()%v", traceInfo(ctx))
 	err := be.Backend.Delete(ctx)
 	debug.Log("  delete err %v", err)
-	return err
+	return annotate(ctx, err)
 }
 
 func (be *Backend) Close() error {
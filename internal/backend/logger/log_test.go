@@ -0,0 +1,39 @@
+package logger_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/backend/logger"
+	"github.com/restic/restic/internal/backend/mem"
+	"github.com/restic/restic/internal/errors"
+	rtest "github.com/restic/restic/internal/test"
+)
+
+func TestTraceID(t *testing.T) {
+	be := logger.New(mem.New())
+	h := backend.Handle{Type: backend.PackFile, Name: "foo"}
+
+	// errors are returned unmodified without a trace id
+	_, err := be.Stat(context.TODO(), h)
+	rtest.Assert(t, be.IsNotExist(err), "expected not exist error, got %v", err)
+	rtest.Assert(t, !strings.Contains(err.Error(), "trace id"), "unexpected trace id in %q", err)
+
+	ctx := backend.WithTraceID(context.TODO(), "req-1234")
+	rtest.Equals(t, "req-1234", backend.TraceID(ctx))
+
+	_, err = be.Stat(ctx, h)
+	rtest.Assert(t, strings.Contains(err.Error(), "trace id req-1234"), "missing trace id in %q", err)
+	rtest.Assert(t, be.IsNotExist(err), "expected not exist error, got %v", err)
+
+	// errors returned by the callback are annotated as well
+	rtest.OK(t, be.Save(ctx, h, backend.NewByteReader([]byte("foobar"), be.Hasher())))
+	testErr := errors.New("test error")
+	err = be.List(ctx, backend.PackFile, func(backend.FileInfo) error {
+		return testErr
+	})
+	rtest.Assert(t, errors.Is(err, testErr), "wrong error %v", err)
+	rtest.Assert(t, strings.Contains(err.Error(), "trace id req-1234"), "missing trace id in %q", err)
+}
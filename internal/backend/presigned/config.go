@@ -0,0 +1,65 @@
+package presigned
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/errors"
+	"github.com/restic/restic/internal/options"
+)
+
+// Config contains all configuration necessary to access a repository via
+// pre-signed URLs issued by a broker service.
+type Config struct {
+	URL   *url.URL
+	Token options.SecretString
+
+	Connections uint `option:"connections" help:"set a limit for the number of concurrent connections (default: 5)"`
+}
+
+func init() {
+	options.Register("presigned", Config{})
+}
+
+// NewConfig returns a new Config with the default values filled in.
+func NewConfig() Config {
+	return Config{
+		Connections: 5,
+	}
+}
+
+// ParseConfig parses the string s and extracts the URL of the broker. The
+// configuration format is presigned:https://broker.example.com/repo/.
+func ParseConfig(s string) (*Config, error) {
+	if !strings.HasPrefix(s, "presigned:") {
+		return nil, errors.New("invalid presigned backend specification")
+	}
+
+	s = s[len("presigned:"):]
+	if !strings.HasSuffix(s, "/") {
+		s += "/"
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, errors.Fatalf("invalid broker URL %q, only http and https are supported", s)
+	}
+
+	cfg := NewConfig()
+	cfg.URL = u
+	return &cfg, nil
+}
+
+var _ backend.ApplyEnvironmenter = &Config{}
+
+// ApplyEnvironment saves values from the environment to the config.
+func (cfg *Config) ApplyEnvironment(prefix string) {
+	if cfg.Token.String() == "" {
+		cfg.Token = options.NewSecretString(os.Getenv(prefix + "RESTIC_PRESIGNED_TOKEN"))
+	}
+}
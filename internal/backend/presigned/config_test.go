@@ -0,0 +1,51 @@
+package presigned
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/restic/restic/internal/backend/test"
+)
+
+func parseURL(s string) *url.URL {
+	u, err := url.Parse(s)
+	if err != nil {
+		panic(err)
+	}
+
+	return u
+}
+
+var configTests = []test.ConfigTestData[Config]{
+	{
+		S: "presigned:https://broker.example.com/repo",
+		Cfg: Config{
+			URL:         parseURL("https://broker.example.com/repo/"),
+			Connections: 5,
+		},
+	},
+	{
+		S: "presigned:http://localhost:8080/",
+		Cfg: Config{
+			URL:         parseURL("http://localhost:8080/"),
+			Connections: 5,
+		},
+	},
+}
+
+func TestParseConfig(t *testing.T) {
+	test.ParseConfigTester(t, ParseConfig, configTests)
+}
+
+func TestParseConfigInvalid(t *testing.T) {
+	for _, s := range []string{
+		"presigned:ftp://broker.example.com/",
+		"presigned:/local/path",
+		"rest:https://broker.example.com/",
+	} {
+		_, err := ParseConfig(s)
+		if err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
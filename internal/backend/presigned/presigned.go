@@ -0,0 +1,447 @@
+// Package presigned implements a backend which accesses the repository using
+// pre-signed URLs. The URLs are issued by an external broker service, such
+// that the host running restic does not require any credentials for the
+// object storage.
+//
+// The broker must implement the following two endpoints relative to its URL:
+//
+//	POST sign
+//	  request:  {"method": "GET", "name": "data/ab/ab12..."}
+//	  response: {"url": "https://...", "expires": "2024-01-02T15:04:05Z"}
+//
+//	GET list?prefix=data/
+//	  response: [{"name": "data/ab/ab12...", "size": 1234}, ...]
+//
+// The sign endpoint is called with the methods GET, HEAD, PUT and DELETE. The
+// expiry time is optional. The list endpoint must return all files below the
+// prefix, including those in subdirectories.
+package presigned
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/backend/layout"
+	"github.com/restic/restic/internal/backend/location"
+	"github.com/restic/restic/internal/backend/util"
+	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/errors"
+
+	"github.com/hashicorp/golang-lru/v2/simplelru"
+)
+
+// make sure the presigned backend implements backend.Backend
+var _ backend.Backend = &Backend{}
+
+// Backend stores data using pre-signed URLs issued by a broker.
+type Backend struct {
+	url         *url.URL
+	token       string
+	connections uint
+	client      http.Client
+	layout.Layout
+
+	mu   sync.Mutex
+	urls *simplelru.LRU[signKey, signedURL]
+}
+
+type signKey struct {
+	method, name string
+}
+
+type signedURL struct {
+	url     string
+	expires time.Time
+}
+
+// signedURLCacheSize is the maximum number of signed URLs kept for reuse.
+const signedURLCacheSize = 1024
+
+// expiryMargin is the minimum remaining validity of a cached URL. This ensures
+// that a request does not start shortly before the URL expires.
+const expiryMargin = 30 * time.Second
+
+func NewFactory() location.Factory {
+	return location.NewHTTPBackendFactory("presigned", ParseConfig, location.NoPassword, Create, Open)
+}
+
+// Open opens the presigned backend with the given config.
+func Open(_ context.Context, cfg Config, rt http.RoundTripper) (*Backend, error) {
+	urls, err := simplelru.NewLRU[signKey, signedURL](signedURLCacheSize, nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "NewLRU")
+	}
+
+	be := &Backend{
+		url:         cfg.URL,
+		token:       cfg.Token.Unwrap(),
+		connections: cfg.Connections,
+		client:      http.Client{Transport: rt},
+		Layout:      &layout.DefaultLayout{Path: "", Join: path.Join},
+		urls:        urls,
+	}
+
+	return be, nil
+}
+
+// Create opens the presigned backend and makes sure that the repository does
+// not exist yet.
+func Create(ctx context.Context, cfg Config, rt http.RoundTripper) (*Backend, error) {
+	be, err := Open(ctx, cfg, rt)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = be.Stat(ctx, backend.Handle{Type: backend.ConfigFile})
+	if err == nil {
+		return nil, errors.New("config file already exists")
+	}
+	if !be.IsNotExist(err) {
+		return nil, err
+	}
+
+	return be, nil
+}
+
+func (be *Backend) Connections() uint {
+	return be.connections
+}
+
+// Location returns the URL of the broker.
+func (be *Backend) Location() string {
+	return be.url.String()
+}
+
+// Hasher may return a hash function for calculating a content hash for the backend
+func (be *Backend) Hasher() hash.Hash {
+	return nil
+}
+
+// HasAtomicReplace returns whether Save() can atomically replace files
+func (be *Backend) HasAtomicReplace() bool {
+	return false
+}
+
+// brokerRequest sends a request to the broker endpoint and decodes the JSON
+// response into result.
+func (be *Backend) brokerRequest(ctx context.Context, method, endpoint string, body interface{}, result interface{}) error {
+	u, err := be.url.Parse(endpoint)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	var rd io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "Marshal")
+		}
+		rd = bytes.NewReader(buf)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), rd)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if be.token != "" {
+		req.Header.Set("Authorization", "Bearer "+be.token)
+	}
+
+	resp, err := be.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "broker")
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_ = drainAndClose(resp)
+		return errors.Errorf("broker response unexpected: %v (%v)", resp.Status, resp.StatusCode)
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(result)
+	if cerr := drainAndClose(resp); err == nil {
+		err = cerr
+	}
+	return errors.Wrap(err, "Decode")
+}
+
+// sign returns a pre-signed URL for method and the file name. Unless refresh is
+// set, a previously issued URL is reused if it is still valid.
+func (be *Backend) sign(ctx context.Context, method, name string, refresh bool) (string, error) {
+	key := signKey{method, name}
+
+	be.mu.Lock()
+	cached, ok := be.urls.Get(key)
+	be.mu.Unlock()
+	if ok && !refresh && (cached.expires.IsZero() || time.Until(cached.expires) > expiryMargin) {
+		return cached.url, nil
+	}
+
+	var res struct {
+		URL     string    `json:"url"`
+		Expires time.Time `json:"expires"`
+	}
+	err := be.brokerRequest(ctx, http.MethodPost, "sign", struct {
+		Method string `json:"method"`
+		Name   string `json:"name"`
+	}{method, name}, &res)
+	if err != nil {
+		return "", err
+	}
+	if res.URL == "" {
+		return "", errors.Errorf("broker returned no URL for %v %v", method, name)
+	}
+
+	debug.Log("signed %v %v, expires %v", method, name, res.Expires)
+	be.mu.Lock()
+	be.urls.Add(key, signedURL{url: res.URL, expires: res.Expires})
+	be.mu.Unlock()
+
+	return res.URL, nil
+}
+
+// do sends a request for the file name to a pre-signed URL. The request is
+// created by newRequest. If the object storage rejects the URL, for example
+// because it has expired, a new URL is requested from the broker and the
+// request is retried once.
+func (be *Backend) do(ctx context.Context, method, name string, newRequest func(url string) (*http.Request, error)) (*http.Response, error) {
+	for refresh := false; ; refresh = true {
+		u, err := be.sign(ctx, method, name, refresh)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := newRequest(u)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := be.client.Do(req)
+		if err != nil {
+			return nil, errors.Wrap(err, "client.Do")
+		}
+
+		if !refresh && (resp.StatusCode == http.StatusUnauthorized || resp.StatusCode == http.StatusForbidden) {
+			debug.Log("URL for %v %v was rejected with %v, refreshing", method, name, resp.Status)
+			_ = drainAndClose(resp)
+			continue
+		}
+
+		return resp, nil
+	}
+}
+
+func drainAndClose(resp *http.Response) error {
+	_, err := io.Copy(io.Discard, resp.Body)
+	cerr := resp.Body.Close()
+
+	// return first error
+	if err != nil {
+		return errors.Errorf("drain: %w", err)
+	}
+	return cerr
+}
+
+// Save stores data in the backend at the handle.
+func (be *Backend) Save(ctx context.Context, h backend.Handle, rd backend.RewindReader) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resp, err := be.do(ctx, http.MethodPut, be.Filename(h), func(u string) (*http.Request, error) {
+		if err := rd.Rewind(); err != nil {
+			return nil, err
+		}
+
+		// make sure that client.Do() cannot close the reader by wrapping it
+		req, err := http.NewRequestWithContext(ctx, http.MethodPut, u, io.NopCloser(rd))
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+		// explicitly set the content length, this prevents chunked encoding
+		req.ContentLength = rd.Length()
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := drainAndClose(resp); err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent {
+		return errors.Errorf("server response unexpected: %v (%v)", resp.Status, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// notExistError is returned whenever the requested file does not exist.
+type notExistError struct {
+	backend.Handle
+}
+
+func (e *notExistError) Error() string {
+	return fmt.Sprintf("%v does not exist", e.Handle)
+}
+
+// IsNotExist returns true if the error was caused by a non-existing file.
+func (be *Backend) IsNotExist(err error) bool {
+	var e *notExistError
+	return errors.As(err, &e)
+}
+
+// Load runs fn with a reader that yields the contents of the file at h at the
+// given offset.
+func (be *Backend) Load(ctx context.Context, h backend.Handle, length int, offset int64, fn func(rd io.Reader) error) error {
+	return util.DefaultLoad(ctx, h, length, offset, be.openReader, fn)
+}
+
+func (be *Backend) openReader(ctx context.Context, h backend.Handle, length int, offset int64) (io.ReadCloser, error) {
+	resp, err := be.do(ctx, http.MethodGet, be.Filename(h), func(u string) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		byteRange := fmt.Sprintf("bytes=%d-", offset)
+		if length > 0 {
+			byteRange = fmt.Sprintf("bytes=%d-%d", offset, offset+int64(length)-1)
+		}
+		req.Header.Set("Range", byteRange)
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		_ = drainAndClose(resp)
+		return nil, &notExistError{h}
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		_ = drainAndClose(resp)
+		return nil, errors.Errorf("unexpected HTTP response (%v): %v", resp.StatusCode, resp.Status)
+	}
+
+	return resp.Body, nil
+}
+
+// Stat returns information about a file.
+func (be *Backend) Stat(ctx context.Context, h backend.Handle) (backend.FileInfo, error) {
+	resp, err := be.do(ctx, http.MethodHead, be.Filename(h), func(u string) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, u, nil)
+		return req, errors.WithStack(err)
+	})
+	if err != nil {
+		return backend.FileInfo{}, err
+	}
+
+	if err = drainAndClose(resp); err != nil {
+		return backend.FileInfo{}, err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return backend.FileInfo{}, &notExistError{h}
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return backend.FileInfo{}, errors.Errorf("unexpected HTTP response (%v): %v", resp.StatusCode, resp.Status)
+	}
+
+	if resp.ContentLength < 0 {
+		return backend.FileInfo{}, errors.New("negative content length")
+	}
+
+	return backend.FileInfo{Size: resp.ContentLength, Name: h.Name}, nil
+}
+
+// Remove removes the file with the given name and type.
+func (be *Backend) Remove(ctx context.Context, h backend.Handle) error {
+	resp, err := be.do(ctx, http.MethodDelete, be.Filename(h), func(u string) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodDelete, u, nil)
+		return req, errors.WithStack(err)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err = drainAndClose(resp); err != nil {
+		return err
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		return &notExistError{h}
+	}
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return errors.Errorf("file not removed, server response: %v (%v)", resp.Status, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// List runs fn for each file in the backend which has the type t. When an
+// error occurs (or fn returns an error), List stops and returns it.
+func (be *Backend) List(ctx context.Context, t backend.FileType, fn func(backend.FileInfo) error) error {
+	prefix, _ := be.Basedir(t)
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	var list []struct {
+		Name string `json:"name"`
+		Size int64  `json:"size"`
+	}
+	err := be.brokerRequest(ctx, http.MethodGet, "list?prefix="+url.QueryEscape(prefix), nil, &list)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range list {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		m := strings.TrimPrefix(item.Name, prefix)
+		if m == "" {
+			continue
+		}
+
+		err := fn(backend.FileInfo{Name: path.Base(m), Size: item.Size})
+		if err != nil {
+			return err
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	return ctx.Err()
+}
+
+// Close does nothing.
+func (be *Backend) Close() error {
+	return nil
+}
+
+// Delete removes all data in the backend.
+func (be *Backend) Delete(ctx context.Context) error {
+	return util.DefaultDelete(ctx, be)
+}
@@ -0,0 +1,299 @@
+package presigned_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/backend/presigned"
+	"github.com/restic/restic/internal/backend/test"
+	rtest "github.com/restic/restic/internal/test"
+)
+
+// mockService implements both a broker and an object storage which accepts
+// the URLs issued by the broker.
+type mockService struct {
+	// validity is the duration for which signed URLs are accepted
+	validity time.Duration
+	// reportedExpiry is the expiry time reported to the client, zero means
+	// that the actual expiry time is reported
+	reportedExpiry time.Duration
+	token          string
+
+	mu         sync.Mutex
+	objects    map[string][]byte
+	signatures map[string]signature
+	signed     int
+	nextSig    int
+}
+
+type signature struct {
+	method, name string
+	expires      time.Time
+}
+
+func newMockService(validity time.Duration) *mockService {
+	return &mockService{
+		validity:   validity,
+		objects:    make(map[string][]byte),
+		signatures: make(map[string]signature),
+	}
+}
+
+func (m *mockService) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	switch {
+	case strings.HasPrefix(req.URL.Path, "/broker/"):
+		if m.token != "" && req.Header.Get("Authorization") != "Bearer "+m.token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		m.serveBroker(w, req)
+	case strings.HasPrefix(req.URL.Path, "/store/"):
+		m.serveStore(w, req)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (m *mockService) serveBroker(w http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	switch {
+	case req.Method == http.MethodPost && req.URL.Path == "/broker/sign":
+		var sreq struct {
+			Method string `json:"method"`
+			Name   string `json:"name"`
+		}
+		if err := json.NewDecoder(req.Body).Decode(&sreq); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		m.nextSig++
+		m.signed++
+		sig := fmt.Sprintf("sig%d", m.nextSig)
+		expires := time.Now().Add(m.validity)
+		m.signatures[sig] = signature{sreq.Method, sreq.Name, expires}
+
+		reported := expires
+		if m.reportedExpiry != 0 {
+			reported = time.Now().Add(m.reportedExpiry)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"url":     "http://" + req.Host + "/store/" + sreq.Name + "?sig=" + sig,
+			"expires": reported,
+		})
+
+	case req.Method == http.MethodGet && req.URL.Path == "/broker/list":
+		prefix := req.URL.Query().Get("prefix")
+		type item struct {
+			Name string `json:"name"`
+			Size int64  `json:"size"`
+		}
+		list := []item{}
+		for name, data := range m.objects {
+			if strings.HasPrefix(name, prefix) {
+				list = append(list, item{name, int64(len(data))})
+			}
+		}
+		_ = json.NewEncoder(w).Encode(list)
+
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (m *mockService) serveStore(w http.ResponseWriter, req *http.Request) {
+	name := strings.TrimPrefix(req.URL.Path, "/store/")
+
+	m.mu.Lock()
+	sig, ok := m.signatures[req.URL.Query().Get("sig")]
+	m.mu.Unlock()
+	if !ok || sig.method != req.Method || sig.name != name || time.Now().After(sig.expires) {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(req.Body)
+		if err != nil || int64(len(data)) != req.ContentLength {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		m.mu.Lock()
+		m.objects[name] = data
+		m.mu.Unlock()
+
+	case http.MethodGet, http.MethodHead:
+		m.mu.Lock()
+		data, ok := m.objects[name]
+		m.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, req, name, time.Time{}, strings.NewReader(string(data)))
+
+	case http.MethodDelete:
+		m.mu.Lock()
+		_, ok := m.objects[name]
+		delete(m.objects, name)
+		m.mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (m *mockService) signCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.signed
+}
+
+func newConfig(srv *httptest.Server) *presigned.Config {
+	cfg, err := presigned.ParseConfig("presigned:" + srv.URL + "/broker/")
+	if err != nil {
+		panic(err)
+	}
+	return cfg
+}
+
+func newTestSuite(t testing.TB) *test.Suite[presigned.Config] {
+	srv := httptest.NewServer(newMockService(time.Hour))
+	t.Cleanup(srv.Close)
+
+	return &test.Suite[presigned.Config]{
+		// NewConfig returns a config for a new temporary backend that will be used in tests.
+		NewConfig: func() (*presigned.Config, error) {
+			return newConfig(srv), nil
+		},
+
+		Factory: presigned.NewFactory(),
+	}
+}
+
+func TestBackendPresigned(t *testing.T) {
+	newTestSuite(t).RunTests(t)
+}
+
+func BenchmarkBackendPresigned(t *testing.B) {
+	newTestSuite(t).RunBenchmarks(t)
+}
+
+func saveAndLoad(t testing.TB, be *presigned.Backend, name string, data string) {
+	h := backend.Handle{Type: backend.PackFile, Name: name}
+	rtest.OK(t, be.Save(context.TODO(), h, backend.NewByteReader([]byte(data), nil)))
+	buf, err := backend.LoadAll(context.TODO(), nil, be, h)
+	rtest.OK(t, err)
+	rtest.Equals(t, data, string(buf))
+}
+
+func TestPresignedURLReuse(t *testing.T) {
+	svc := newMockService(time.Hour)
+	srv := httptest.NewServer(svc)
+	defer srv.Close()
+
+	be, err := presigned.Open(context.TODO(), *newConfig(srv), http.DefaultTransport)
+	rtest.OK(t, err)
+
+	saveAndLoad(t, be, "aabbcc", "foobar")
+	rtest.Equals(t, 2, svc.signCount())
+
+	// valid URLs are reused
+	_, err = be.Stat(context.TODO(), backend.Handle{Type: backend.PackFile, Name: "aabbcc"})
+	rtest.OK(t, err)
+	_, err = be.Stat(context.TODO(), backend.Handle{Type: backend.PackFile, Name: "aabbcc"})
+	rtest.OK(t, err)
+	rtest.Equals(t, 3, svc.signCount())
+}
+
+func TestPresignedURLExpiry(t *testing.T) {
+	svc := newMockService(time.Second)
+	srv := httptest.NewServer(svc)
+	defer srv.Close()
+
+	be, err := presigned.Open(context.TODO(), *newConfig(srv), http.DefaultTransport)
+	rtest.OK(t, err)
+
+	// URLs which expire soon are not reused
+	saveAndLoad(t, be, "aabbcc", "foobar")
+	saveAndLoad(t, be, "aabbcc", "foobaz")
+	rtest.Equals(t, 4, svc.signCount())
+}
+
+func TestPresignedURLRefresh(t *testing.T) {
+	svc := newMockService(100 * time.Millisecond)
+	// pretend that the URLs are valid for much longer
+	svc.reportedExpiry = time.Hour
+	srv := httptest.NewServer(svc)
+	defer srv.Close()
+
+	be, err := presigned.Open(context.TODO(), *newConfig(srv), http.DefaultTransport)
+	rtest.OK(t, err)
+
+	saveAndLoad(t, be, "aabbcc", "foobar")
+	rtest.Equals(t, 2, svc.signCount())
+
+	// the cached URLs are rejected by the object storage and must be refreshed
+	time.Sleep(200 * time.Millisecond)
+	saveAndLoad(t, be, "aabbcc", "foobaz")
+	rtest.Equals(t, 4, svc.signCount())
+}
+
+func TestPresignedToken(t *testing.T) {
+	svc := newMockService(time.Hour)
+	svc.token = "secret"
+	srv := httptest.NewServer(svc)
+	defer srv.Close()
+
+	cfg := newConfig(srv)
+	be, err := presigned.Open(context.TODO(), *cfg, http.DefaultTransport)
+	rtest.OK(t, err)
+	_, err = be.Stat(context.TODO(), backend.Handle{Type: backend.ConfigFile})
+	rtest.Assert(t, err != nil && !be.IsNotExist(err), "expected broker error, got %v", err)
+
+	t.Setenv("RESTIC_PRESIGNED_TOKEN", "secret")
+	cfg.ApplyEnvironment("")
+	be, err = presigned.Open(context.TODO(), *cfg, http.DefaultTransport)
+	rtest.OK(t, err)
+	_, err = be.Stat(context.TODO(), backend.Handle{Type: backend.ConfigFile})
+	rtest.Assert(t, be.IsNotExist(err), "expected not exist error, got %v", err)
+}
+
+func TestPresignedList(t *testing.T) {
+	svc := newMockService(time.Hour)
+	srv := httptest.NewServer(svc)
+	defer srv.Close()
+
+	be, err := presigned.Open(context.TODO(), *newConfig(srv), http.DefaultTransport)
+	rtest.OK(t, err)
+
+	names := []string{"aa11", "aa22", "bb33"}
+	for _, name := range names {
+		saveAndLoad(t, be, name, name)
+	}
+	rtest.OK(t, be.Save(context.TODO(), backend.Handle{Type: backend.IndexFile, Name: "cc44"}, backend.NewByteReader([]byte("index"), nil)))
+
+	var found []string
+	rtest.OK(t, be.List(context.TODO(), backend.PackFile, func(fi backend.FileInfo) error {
+		found = append(found, fi.Name)
+		rtest.Equals(t, int64(4), fi.Size)
+		return nil
+	}))
+	sort.Strings(found)
+	rtest.Equals(t, names, found)
+}
@@ -27,15 +27,18 @@ import (
 	"golang.org/x/net/http2"
 )
 
-// Backend is used to access data stored somewhere via rclone.
+// Backend is used to access data stored somewhere via rclone. If the rclone
+// process exits unexpectedly, it is restarted and the failed request is
+// issued once more.
 type Backend struct {
 	*rest.Backend
-	tr         *http2.Transport
-	cmd        *exec.Cmd
-	waitCh     <-chan struct{}
-	waitResult error
-	wg         *sync.WaitGroup
-	conn       *StdioConn
+	cfg Config
+	lim limiter.Limiter
+
+	mu       sync.Mutex
+	proc     *process
+	restarts uint
+	closed   bool
 }
 
 func NewFactory() location.Factory {
@@ -139,18 +142,56 @@ func wrapConn(c *StdioConn, lim limiter.Limiter) *wrappedConn {
 	return wc
 }
 
-// New initializes a Backend and starts the process.
-func newBackend(ctx context.Context, cfg Config, lim limiter.Limiter) (*Backend, error) {
-	var (
-		args []string
-		err  error
-	)
+// process is a running rclone instance.
+type process struct {
+	tr         *http2.Transport
+	cmd        *exec.Cmd
+	waitCh     <-chan struct{}
+	waitResult error
+	wg         *sync.WaitGroup
+	conn       *StdioConn
+}
+
+// exited reports whether the process has exited. It waits up to timeout for
+// the process to exit.
+func (p *process) exited(timeout time.Duration) bool {
+	select {
+	case <-p.waitCh:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// close terminates the process.
+func (p *process) close() error {
+	p.tr.CloseIdleConnections()
+
+	select {
+	case <-p.waitCh:
+		debug.Log("rclone exited")
+	case <-time.After(waitForExit):
+		debug.Log("timeout, closing file descriptors")
+		err := p.conn.CloseAll()
+		if err != nil {
+			return err
+		}
+	}
+
+	p.wg.Wait()
+	debug.Log("wait for rclone returned: %v", p.waitResult)
+	return p.waitResult
+}
+
+// commandArgs returns the program and the arguments to run rclone for cfg.
+func commandArgs(cfg Config) (string, []string, error) {
+	var args []string
 
 	// build program args, start with the program
 	if cfg.Program != "" {
 		a, err := backend.SplitShellStrings(cfg.Program)
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
 		args = append(args, a...)
 	}
@@ -159,7 +200,7 @@ func newBackend(ctx context.Context, cfg Config, lim limiter.Limiter) (*Backend,
 	if cfg.Args != "" {
 		a, err := backend.SplitShellStrings(cfg.Args)
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
 
 		args = append(args, a...)
@@ -167,8 +208,32 @@ func newBackend(ctx context.Context, cfg Config, lim limiter.Limiter) (*Backend,
 
 	// finally, add the remote
 	args = append(args, cfg.Remote)
-	arg0, args := args[0], args[1:]
+	return args[0], args[1:], nil
+}
+
+// New initializes a Backend and starts the process.
+func newBackend(ctx context.Context, cfg Config, lim limiter.Limiter) (*Backend, error) {
+	arg0, args, err := commandArgs(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	warnOutdatedVersion(ctx, arg0)
+
+	proc, err := startProcess(ctx, cfg, lim, arg0, args)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Backend{
+		cfg:  cfg,
+		lim:  lim,
+		proc: proc,
+	}, nil
+}
 
+// startProcess runs rclone and waits until it accepts HTTP requests.
+func startProcess(ctx context.Context, cfg Config, lim limiter.Limiter, arg0 string, args []string) (*process, error) {
 	debug.Log("running command: %v %v", arg0, args)
 	stdioConn, wg, waitCh, bg, err := run(arg0, args...)
 	if err != nil {
@@ -195,7 +260,7 @@ func newBackend(ctx context.Context, cfg Config, lim limiter.Limiter) (*Backend,
 	}
 
 	cmd := stdioConn.cmd
-	be := &Backend{
+	proc := &process{
 		tr:     tr,
 		cmd:    cmd,
 		waitCh: waitCh,
@@ -215,7 +280,7 @@ func newBackend(ctx context.Context, cfg Config, lim limiter.Limiter) (*Backend,
 		// according to the documentation of StdErrPipe, Wait() must only be called after the former has completed
 		err := cmd.Wait()
 		debug.Log("Wait returned %v", err)
-		be.waitResult = err
+		proc.waitResult = err
 		// close our side of the pipes to rclone, ignore errors
 		_ = stdioConn.CloseAll()
 	}()
@@ -245,21 +310,28 @@ func newBackend(ctx context.Context, cfg Config, lim limiter.Limiter) (*Backend,
 		// wait for rclone to exit
 		wg.Wait()
 		// try to return the program exit code if communication with rclone has failed
-		if be.waitResult != nil && (errors.Is(err, context.Canceled) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed)) {
-			err = be.waitResult
+		if proc.waitResult != nil && (errors.Is(err, context.Canceled) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.EPIPE) || errors.Is(err, os.ErrClosed)) {
+			err = proc.waitResult
 		}
 
 		return nil, fmt.Errorf("error talking HTTP to rclone: %w", err)
 	}
 
 	_ = res.Body.Close()
+	if res.StatusCode >= 500 {
+		// rclone is running, but cannot access the remote
+		_ = bg()
+		_ = cmd.Process.Kill()
+		wg.Wait()
+		return nil, errors.Errorf("rclone health check failed: unexpected HTTP response (%v): %v", res.StatusCode, res.Status)
+	}
 	debug.Log("HTTP status %q returned, moving instance to background", res.Status)
 	err = bg()
 	if err != nil {
 		return nil, fmt.Errorf("error moving process to background: %w", err)
 	}
 
-	return be, nil
+	return proc, nil
 }
 
 // Open starts an rclone process with the given config.
@@ -279,7 +351,7 @@ func Open(ctx context.Context, cfg Config, lim limiter.Limiter) (*Backend, error
 		URL:         url,
 	}
 
-	restBackend, err := rest.Open(ctx, restConfig, debug.RoundTripper(be.tr))
+	restBackend, err := rest.Open(ctx, restConfig, debug.RoundTripper(transport{be}))
 	if err != nil {
 		_ = be.Close()
 		return nil, err
@@ -308,7 +380,7 @@ func Create(ctx context.Context, cfg Config, lim limiter.Limiter) (*Backend, err
 		URL:         url,
 	}
 
-	restBackend, err := rest.Create(ctx, restConfig, debug.RoundTripper(be.tr))
+	restBackend, err := rest.Create(ctx, restConfig, debug.RoundTripper(transport{be}))
 	if err != nil {
 		_ = be.Close()
 		return nil, err
@@ -323,20 +395,8 @@ const waitForExit = 5 * time.Second
 // Close terminates the backend.
 func (be *Backend) Close() error {
 	debug.Log("exiting rclone")
-	be.tr.CloseIdleConnections()
-
-	select {
-	case <-be.waitCh:
-		debug.Log("rclone exited")
-	case <-time.After(waitForExit):
-		debug.Log("timeout, closing file descriptors")
-		err := be.conn.CloseAll()
-		if err != nil {
-			return err
-		}
-	}
-
-	be.wg.Wait()
-	debug.Log("wait for rclone returned: %v", be.waitResult)
-	return be.waitResult
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	be.closed = true
+	return be.proc.close()
 }
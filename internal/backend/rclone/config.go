@@ -15,6 +15,7 @@ type Config struct {
 	Remote      string
 	Connections uint          `option:"connections" help:"set a limit for the number of concurrent connections (default: 5)"`
 	Timeout     time.Duration `option:"timeout"     help:"set a timeout limit to wait for rclone to establish a connection (default: 1m)"`
+	MaxRestarts uint          `option:"max-restarts" help:"restart rclone at most this many times if it exits unexpectedly (default: 3)"`
 }
 
 var defaultConfig = Config{
@@ -22,6 +23,7 @@ var defaultConfig = Config{
 	Args:        "serve restic --stdio --b2-hard-delete",
 	Connections: 5,
 	Timeout:     time.Minute,
+	MaxRestarts: 3,
 }
 
 func init() {
@@ -15,6 +15,8 @@ func TestRcloneExit(t *testing.T) {
 	dir := rtest.TempDir(t)
 	cfg := NewConfig()
 	cfg.Remote = dir
+	// don't restart rclone
+	cfg.MaxRestarts = 0
 	be, err := Open(context.TODO(), cfg, nil)
 	var e *exec.Error
 	if errors.As(err, &e) && e.Err == exec.ErrNotFound {
@@ -27,7 +29,7 @@ func TestRcloneExit(t *testing.T) {
 		_ = be.Close()
 	}()
 
-	err = be.cmd.Process.Kill()
+	err = be.proc.cmd.Process.Kill()
 	rtest.OK(t, err)
 	t.Log("killed rclone")
 
@@ -0,0 +1,137 @@
+package rclone
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/errors"
+)
+
+// exitGracePeriod is the time to wait for the rclone process to exit after a
+// request to it has failed.
+const exitGracePeriod = time.Second
+
+// transport passes requests to the currently running rclone process.
+type transport struct {
+	be *Backend
+}
+
+func (t transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.be.current().tr.RoundTrip(req)
+}
+
+func (be *Backend) current() *process {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+	return be.proc
+}
+
+// restart replaces the process failed by a new rclone process. Nothing happens
+// if another request has already restarted rclone.
+func (be *Backend) restart(ctx context.Context, failed *process) error {
+	be.mu.Lock()
+	defer be.mu.Unlock()
+
+	if be.proc != failed {
+		return nil
+	}
+	if be.closed {
+		return errors.New("backend is already closed")
+	}
+	if be.restarts >= be.cfg.MaxRestarts {
+		return errors.Errorf("rclone was already restarted %d times", be.restarts)
+	}
+	be.restarts++
+
+	exitErr := failed.close()
+	debug.Log("rclone exited with %v, restart %d of %d", exitErr, be.restarts, be.cfg.MaxRestarts)
+	fmt.Fprintf(os.Stderr, "rclone exited unexpectedly (%v), restarting\n", exitErr)
+
+	arg0, args, err := commandArgs(be.cfg)
+	if err != nil {
+		return err
+	}
+	proc, err := startProcess(ctx, be.cfg, be.lim, arg0, args)
+	if err != nil {
+		return err
+	}
+	be.proc = proc
+	return nil
+}
+
+// retryOnExit runs fn. If fn fails because the rclone process has exited, then
+// rclone is restarted and fn is run once more. If rewind is not nil, it is
+// called before running fn again.
+func (be *Backend) retryOnExit(ctx context.Context, fn func() error, rewind func() error) error {
+	proc := be.current()
+	err := fn()
+	if err == nil || ctx.Err() != nil || be.IsNotExist(err) || !proc.exited(exitGracePeriod) {
+		return err
+	}
+
+	if rerr := be.restart(ctx, proc); rerr != nil {
+		return fmt.Errorf("%w (restarting rclone failed: %v)", err, rerr)
+	}
+	if rewind != nil {
+		if rerr := rewind(); rerr != nil {
+			return err
+		}
+	}
+	debug.Log("reissuing request failed with %v", err)
+	return fn()
+}
+
+// Save stores data in the backend at the handle.
+func (be *Backend) Save(ctx context.Context, h backend.Handle, rd backend.RewindReader) error {
+	return be.retryOnExit(ctx, func() error {
+		return be.Backend.Save(ctx, h, rd)
+	}, rd.Rewind)
+}
+
+// Load runs fn with a reader that yields the contents of the file at h at the
+// given offset.
+func (be *Backend) Load(ctx context.Context, h backend.Handle, length int, offset int64, fn func(rd io.Reader) error) error {
+	return be.retryOnExit(ctx, func() error {
+		return be.Backend.Load(ctx, h, length, offset, fn)
+	}, nil)
+}
+
+// Stat returns information about a blob.
+func (be *Backend) Stat(ctx context.Context, h backend.Handle) (fi backend.FileInfo, err error) {
+	err = be.retryOnExit(ctx, func() error {
+		fi, err = be.Backend.Stat(ctx, h)
+		return err
+	}, nil)
+	return fi, err
+}
+
+// Remove removes the blob with the given name and type.
+func (be *Backend) Remove(ctx context.Context, h backend.Handle) error {
+	return be.retryOnExit(ctx, func() error {
+		return be.Backend.Remove(ctx, h)
+	}, nil)
+}
+
+// List runs fn for each file in the backend which has the type t. The listing
+// is only reissued if fn has not been called yet, as fn must be called at most
+// once for each file. rclone is restarted in either case.
+func (be *Backend) List(ctx context.Context, t backend.FileType, fn func(backend.FileInfo) error) error {
+	called := false
+	var err error
+	return be.retryOnExit(ctx, func() error {
+		if called {
+			return err
+		}
+		err = be.Backend.List(ctx, t, func(fi backend.FileInfo) error {
+			called = true
+			return fn(fi)
+		})
+		return err
+	}, nil)
+}
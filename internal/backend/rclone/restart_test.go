@@ -0,0 +1,170 @@
+package rclone
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/restic/restic/internal/backend"
+	rtest "github.com/restic/restic/internal/test"
+	"golang.org/x/net/http2"
+)
+
+func TestMain(m *testing.M) {
+	// the test binary acts as a fake rclone if this variable is set
+	if os.Getenv("RESTIC_TEST_FAKE_RCLONE") != "" {
+		serveFakeRclone(os.Args[len(os.Args)-1])
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// serveFakeRclone implements the parts of "rclone serve restic --stdio" which
+// are used by the tests, files are stored in dir. If the file "crash" exists
+// in dir, the process exits in the middle of the next request with the
+// method contained in that file.
+func serveFakeRclone(dir string) {
+	handler := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		fmt.Fprintf(os.Stderr, "DBG %v %v\n", req.Method, req.URL.Path)
+		filename := filepath.Join(dir, filepath.FromSlash(req.URL.Path))
+		crash := false
+		if method, err := os.ReadFile(filepath.Join(dir, "crash")); err == nil && string(method) == req.Method {
+			_ = os.Remove(filepath.Join(dir, "crash"))
+			crash = true
+		}
+
+		switch {
+		case req.Method == http.MethodGet && strings.HasSuffix(req.URL.Path, "/"):
+			type item struct {
+				Name string `json:"name"`
+				Size int64  `json:"size"`
+			}
+			list := []item{}
+			entries, _ := os.ReadDir(filename)
+			for _, entry := range entries {
+				fi, err := entry.Info()
+				if err == nil {
+					list = append(list, item{entry.Name(), fi.Size()})
+				}
+			}
+			w.Header().Set("Content-Type", "application/vnd.x.restic.rest.v2")
+			_ = json.NewEncoder(w).Encode(list)
+
+		case req.Method == http.MethodGet || req.Method == http.MethodHead:
+			f, err := os.Open(filename)
+			if err != nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			defer func() { _ = f.Close() }()
+			if crash {
+				// send part of the response, then die
+				w.WriteHeader(http.StatusOK)
+				_, _ = io.CopyN(w, f, 10)
+				w.(http.Flusher).Flush()
+				os.Exit(1)
+			}
+			http.ServeContent(w, req, "", fi(f).ModTime(), f)
+
+		case req.Method == http.MethodPost:
+			data, err := io.ReadAll(req.Body)
+			if err != nil || crash {
+				os.Exit(1)
+			}
+			_ = os.MkdirAll(filepath.Dir(filename), 0700)
+			if err := os.WriteFile(filename, data, 0600); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+
+		case req.Method == http.MethodDelete:
+			if crash {
+				os.Exit(1)
+			}
+			if err := os.Remove(filename); err != nil {
+				w.WriteHeader(http.StatusNotFound)
+			}
+
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	})
+
+	conn := &StdioConn{receive: os.Stdin, send: os.Stdout}
+	(&http2.Server{}).ServeConn(conn, &http2.ServeConnOpts{Handler: handler})
+}
+
+func fi(f *os.File) os.FileInfo {
+	fi, err := f.Stat()
+	if err != nil {
+		panic(err)
+	}
+	return fi
+}
+
+func openFakeRclone(t *testing.T, dir string, maxRestarts uint) *Backend {
+	t.Setenv("RESTIC_TEST_FAKE_RCLONE", "1")
+	cfg := NewConfig()
+	cfg.Program = os.Args[0]
+	cfg.Args = ""
+	cfg.Remote = dir
+	cfg.MaxRestarts = maxRestarts
+
+	be, err := Open(context.TODO(), cfg, nil)
+	rtest.OK(t, err)
+	t.Cleanup(func() {
+		_ = be.Close()
+	})
+	return be
+}
+
+func crashOn(t *testing.T, dir, method string) {
+	rtest.OK(t, os.WriteFile(filepath.Join(dir, "crash"), []byte(method), 0600))
+}
+
+func TestRcloneRestart(t *testing.T) {
+	dir := rtest.TempDir(t)
+	be := openFakeRclone(t, dir, 3)
+	ctx := context.TODO()
+
+	data := rtest.Random(23, 1000)
+	h := backend.Handle{Type: backend.PackFile, Name: "0123456789abcdef"}
+
+	// rclone dies after receiving the upload
+	crashOn(t, dir, http.MethodPost)
+	rtest.OK(t, be.Save(ctx, h, backend.NewByteReader(data, be.Hasher())))
+
+	// rclone dies while sending the file
+	crashOn(t, dir, http.MethodGet)
+	buf, err := backend.LoadAll(ctx, nil, be, h)
+	rtest.OK(t, err)
+	rtest.Assert(t, bytes.Equal(data, buf), "wrong data returned")
+
+	// rclone is killed between requests
+	rtest.OK(t, be.current().cmd.Process.Kill())
+	fi, err := be.Stat(ctx, h)
+	rtest.OK(t, err)
+	rtest.Equals(t, int64(len(data)), fi.Size)
+
+	// no restarts left
+	crashOn(t, dir, http.MethodDelete)
+	err = be.Remove(ctx, h)
+	rtest.Assert(t, err != nil && strings.Contains(err.Error(), "restarting rclone failed"),
+		"expected restart to fail, got %v", err)
+}
+
+func TestRcloneNoRestart(t *testing.T) {
+	dir := rtest.TempDir(t)
+	be := openFakeRclone(t, dir, 0)
+
+	crashOn(t, dir, http.MethodPost)
+	h := backend.Handle{Type: backend.PackFile, Name: "0123456789abcdef"}
+	err := be.Save(context.TODO(), h, backend.NewByteReader([]byte("foobar"), be.Hasher()))
+	rtest.Assert(t, err != nil, "expected an error")
+}
@@ -0,0 +1,102 @@
+package rclone
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/errors"
+)
+
+// minVersion is the oldest rclone version which is known to work with restic.
+var minVersion = version{1, 52, 0}
+
+// versionTimeout limits how long querying the rclone version may take.
+const versionTimeout = 10 * time.Second
+
+type version [3]int
+
+func (v version) String() string {
+	return fmt.Sprintf("v%d.%d.%d", v[0], v[1], v[2])
+}
+
+func (v version) less(other version) bool {
+	for i := range v {
+		if v[i] != other[i] {
+			return v[i] < other[i]
+		}
+	}
+	return false
+}
+
+var versionRegexp = regexp.MustCompile(`^rclone v(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseVersion extracts the version from the output of "rclone version".
+func parseVersion(output string) (version, error) {
+	m := versionRegexp.FindStringSubmatch(strings.TrimSpace(output))
+	if m == nil {
+		return version{}, errors.Errorf("unable to parse rclone version from %q", output)
+	}
+
+	var v version
+	for i, s := range m[1:] {
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return version{}, errors.Errorf("invalid rclone version %q", m[0])
+		}
+		v[i] = n
+	}
+	return v, nil
+}
+
+// queryVersion runs "program version" and returns the reported version.
+func queryVersion(ctx context.Context, program string) (version, error) {
+	ctx, cancel := context.WithTimeout(ctx, versionTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, program, "version").Output()
+	if err != nil {
+		return version{}, errors.Wrap(err, "rclone version")
+	}
+	return parseVersion(string(out))
+}
+
+// checkVersion returns a warning if the rclone version is older than
+// minVersion. The version is only checked if program is rclone itself and not
+// for example a wrapper script, errors while determining the version are
+// ignored.
+func checkVersion(ctx context.Context, program string) string {
+	name := strings.TrimSuffix(filepath.Base(program), ".exe")
+	if name != "rclone" {
+		debug.Log("skipping version check for %v", program)
+		return ""
+	}
+
+	v, err := queryVersion(ctx, program)
+	if err != nil {
+		debug.Log("unable to determine rclone version: %v", err)
+		return ""
+	}
+	debug.Log("rclone version %v", v)
+
+	if v.less(minVersion) {
+		return fmt.Sprintf("rclone %v is older than %v, which is the oldest version known to work with restic, please upgrade rclone", v, minVersion)
+	}
+	return ""
+}
+
+func warnOutdatedVersion(ctx context.Context, program string) {
+	if msg := checkVersion(ctx, program); msg != "" {
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", msg)
+	}
+}
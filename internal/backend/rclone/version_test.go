@@ -0,0 +1,75 @@
+package rclone
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	rtest "github.com/restic/restic/internal/test"
+)
+
+func TestParseVersion(t *testing.T) {
+	for _, test := range []struct {
+		output  string
+		version version
+		err     bool
+	}{
+		{"rclone v1.65.0\n- os/version: debian 12\n", version{1, 65, 0}, false},
+		{"rclone v1.52.3-DEV\n", version{1, 52, 3}, false},
+		{"rclone v1.41\n", version{1, 41, 0}, false},
+		{"rclone version unknown\n", version{}, true},
+		{"", version{}, true},
+	} {
+		v, err := parseVersion(test.output)
+		if test.err {
+			rtest.Assert(t, err != nil, "expected error for %q", test.output)
+			continue
+		}
+		rtest.OK(t, err)
+		rtest.Equals(t, test.version, v)
+	}
+}
+
+// writeFakeRclone creates a program called rclone which prints output when
+// called with the argument "version".
+func writeFakeRclone(t testing.TB, output string) string {
+	program := filepath.Join(rtest.TempDir(t), "rclone")
+	script := "#!/bin/sh\nif [ \"$1\" = version ]; then\n  printf '" + output + "'\nfi\n"
+	rtest.OK(t, os.WriteFile(program, []byte(script), 0700))
+	return program
+}
+
+func TestCheckVersion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake rclone requires a shell")
+	}
+
+	for _, test := range []struct {
+		output string
+		warn   bool
+	}{
+		{`rclone v1.40.0\n`, true},
+		{`rclone v1.51.9\n`, true},
+		{`rclone v1.52.0\n`, false},
+		{`rclone v1.65.2\n- go/version: go1.21.5\n`, false},
+		{`rclone v2.0\n`, false},
+		// unknown versions are ignored
+		{`garbage\n`, false},
+	} {
+		program := writeFakeRclone(t, test.output)
+		msg := checkVersion(context.TODO(), program)
+		rtest.Assert(t, (msg != "") == test.warn, "output %q: unexpected warning %q", test.output, msg)
+		if test.warn {
+			rtest.Assert(t, strings.Contains(msg, minVersion.String()), "warning does not contain the minimum version: %q", msg)
+		}
+	}
+
+	// only rclone itself is checked
+	program := writeFakeRclone(t, `rclone v1.40.0\n`)
+	wrapper := filepath.Join(filepath.Dir(program), "rclone-wrapper")
+	rtest.OK(t, os.Rename(program, wrapper))
+	rtest.Equals(t, "", checkVersion(context.TODO(), wrapper))
+}
@@ -28,6 +28,7 @@ type Config struct {
 	Region        string `option:"region" help:"set region"`
 	BucketLookup  string `option:"bucket-lookup" help:"bucket lookup style: 'auto', 'dns', or 'path'"`
 	ListObjectsV1 bool   `option:"list-objects-v1" help:"use deprecated V1 api for ListObjects calls"`
+	ListRateLimit uint   `option:"list-rate-limit" help:"set a limit for the number of list requests per second (default: unlimited)"`
 }
 
 // NewConfig returns a new Config with the default values filled in.
@@ -0,0 +1,108 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/options"
+	rtest "github.com/restic/restic/internal/test"
+)
+
+// newListServer returns a server which simulates a bucket listing with the
+// given number of pages and records the time of each list request.
+func newListServer(t *testing.T, pages int) (*httptest.Server, func() []time.Time) {
+	var m sync.Mutex
+	var requests []time.Time
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !isListRequest(req) {
+			t.Errorf("unexpected request %v %v", req.Method, req.URL)
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		m.Lock()
+		requests = append(requests, time.Now())
+		m.Unlock()
+
+		page := 0
+		if token := req.URL.Query().Get("continuation-token"); token != "" {
+			var err error
+			page, err = strconv.Atoi(token)
+			if err != nil {
+				t.Errorf("invalid continuation token %q", token)
+			}
+		}
+
+		prefix := req.URL.Query().Get("prefix")
+		var sb strings.Builder
+		sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?>`)
+		sb.WriteString(`<ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+		fmt.Fprintf(&sb, "<Name>bucket</Name><Prefix>%s</Prefix><KeyCount>1</KeyCount><MaxKeys>1</MaxKeys>", prefix)
+		if page < pages-1 {
+			fmt.Fprintf(&sb, "<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>", page+1)
+		} else {
+			sb.WriteString("<IsTruncated>false</IsTruncated>")
+		}
+		fmt.Fprintf(&sb, "<Contents><Key>%sfile%d</Key><Size>%d</Size></Contents>", prefix, page, page)
+		sb.WriteString("</ListBucketResult>")
+
+		w.Header().Set("Content-Type", "application/xml")
+		_, _ = w.Write([]byte(sb.String()))
+	}))
+
+	return srv, func() []time.Time {
+		m.Lock()
+		defer m.Unlock()
+		return append([]time.Time{}, requests...)
+	}
+}
+
+func TestListRateLimit(t *testing.T) {
+	const pages = 6
+	const pagesPerSecond = 10
+
+	srv, requests := newListServer(t, pages)
+	defer srv.Close()
+
+	cfg := NewConfig()
+	cfg.Endpoint = strings.TrimPrefix(srv.URL, "http://")
+	cfg.UseHTTP = true
+	cfg.Bucket = "bucket"
+	cfg.Prefix = "restic"
+	cfg.Layout = "default"
+	cfg.Region = "us-east-1"
+	cfg.BucketLookup = "path"
+	cfg.KeyID = "key"
+	cfg.Secret = options.NewSecretString("secret")
+	cfg.ListRateLimit = pagesPerSecond
+
+	be, err := open(context.TODO(), cfg, http.DefaultTransport)
+	rtest.OK(t, err)
+
+	var names []string
+	rtest.OK(t, be.List(context.TODO(), backend.IndexFile, func(fi backend.FileInfo) error {
+		names = append(names, fi.Name)
+		return nil
+	}))
+	rtest.Equals(t, pages, len(names))
+
+	times := requests()
+	rtest.Equals(t, pages, len(times))
+	minInterval := time.Second / pagesPerSecond
+	for i := 1; i < len(times); i++ {
+		// allow for some jitter between the client and the server
+		interval := times[i].Sub(times[i-1])
+		rtest.Assert(t, interval >= minInterval*8/10, "page %d was requested after only %v", i, interval)
+	}
+	total := times[len(times)-1].Sub(times[0])
+	rtest.Assert(t, total >= (pages-1)*minInterval*9/10, "%d pages were requested within %v", pages, total)
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/restic/restic/internal/backend"
 	"github.com/restic/restic/internal/backend/layout"
+	"github.com/restic/restic/internal/backend/limiter"
 	"github.com/restic/restic/internal/backend/location"
 	"github.com/restic/restic/internal/backend/util"
 	"github.com/restic/restic/internal/debug"
@@ -56,6 +57,10 @@ func open(ctx context.Context, cfg Config, rt http.RoundTripper) (*Backend, erro
 		return nil, errors.Wrap(err, "s3.getCredentials")
 	}
 
+	// minio implements the pagination loop of List internally, thus limit
+	// the list requests on the transport level
+	rt = limiter.NewListLimiter(cfg.ListRateLimit).Transport(rt, isListRequest)
+
 	options := &minio.Options{
 		Creds:     creds,
 		Secure:    !cfg.UseHTTP,
@@ -94,6 +99,12 @@ func open(ctx context.Context, cfg Config, rt http.RoundTripper) (*Backend, erro
 	return be, nil
 }
 
+// isListRequest returns true for requests which list objects in a bucket.
+// Both versions of the ListObjects API always include the prefix parameter.
+func isListRequest(req *http.Request) bool {
+	return req.Method == http.MethodGet && req.URL.Query().Has("prefix")
+}
+
 // getCredentials -- runs through the various credential types and returns the first one that works.
 // additionally if the user has specified a role to assume, it will do that as well.
 func getCredentials(cfg Config) (*credentials.Credentials, error) {
@@ -468,8 +479,9 @@ func (be *Backend) List(ctx context.Context, t backend.FileType, fn func(backend
 		}
 
 		fi := backend.FileInfo{
-			Name: path.Base(m),
-			Size: obj.Size,
+			Name:    path.Base(m),
+			Size:    obj.Size,
+			ModTime: obj.LastModified,
 		}
 
 		if ctx.Err() != nil {
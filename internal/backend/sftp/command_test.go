@@ -0,0 +1,85 @@
+//go:build !windows
+// +build !windows
+
+package sftp_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/restic/restic/internal/backend/sftp"
+	rtest "github.com/restic/restic/internal/test"
+)
+
+// writeFakeCommand creates a shell script with the given name which records
+// its arguments in the file argsfile and then runs exec.
+func writeFakeCommand(t testing.TB, name, exec string) (script, argsfile string) {
+	dir := rtest.TempDir(t)
+	script = filepath.Join(dir, name)
+	argsfile = filepath.Join(dir, "args")
+
+	content := fmt.Sprintf("#!/bin/sh\necho \"$@\" > %q\n%s\n", argsfile, exec)
+	rtest.OK(t, os.WriteFile(script, []byte(content), 0700))
+	return script, argsfile
+}
+
+func TestCommandNoSFTPSession(t *testing.T) {
+	script, argsfile := writeFakeCommand(t, "fake-ssh", "exit 3")
+
+	cfg := sftp.NewConfig()
+	cfg.Path = rtest.TempDir(t)
+	cfg.Command = fmt.Sprintf("%q -J bastion host -s sftp", script)
+
+	_, err := sftp.Open(context.TODO(), cfg)
+	rtest.Assert(t, err != nil, "expected error for command without sftp session")
+	rtest.Assert(t, strings.Contains(err.Error(), "unable to start the sftp session") &&
+		strings.Contains(err.Error(), "exit status 3"), "unexpected error %v", err)
+
+	args, err := os.ReadFile(argsfile)
+	rtest.OK(t, err)
+	rtest.Equals(t, "-J bastion host -s sftp\n", string(args))
+}
+
+func TestCommandSFTPSession(t *testing.T) {
+	if sftpServer == "" {
+		t.Skip("sftp server binary not found")
+	}
+
+	script, argsfile := writeFakeCommand(t, "fake-ssh", fmt.Sprintf("exec %q -e", sftpServer))
+
+	cfg := sftp.NewConfig()
+	cfg.Path = rtest.TempDir(t)
+	cfg.Command = fmt.Sprintf("%q -J bastion 'host name' -s sftp", script)
+
+	be, err := sftp.Create(context.TODO(), cfg)
+	rtest.OK(t, err)
+	rtest.OK(t, be.Close())
+
+	args, err := os.ReadFile(argsfile)
+	rtest.OK(t, err)
+	rtest.Equals(t, "-J bastion host name -s sftp\n", string(args))
+}
+
+func TestKeepaliveOptions(t *testing.T) {
+	script, argsfile := writeFakeCommand(t, "ssh", "exit 1")
+	// make sure the fake ssh is used
+	t.Setenv("PATH", filepath.Dir(script))
+
+	cfg := sftp.NewConfig()
+	cfg.Host = "host"
+	cfg.Path = "dir"
+	cfg.KeepaliveInterval = 30 * time.Second
+	cfg.KeepaliveMax = 4
+
+	_, err := sftp.Open(context.TODO(), cfg)
+	rtest.Assert(t, err != nil, "expected error for fake ssh command")
+
+	args, err := os.ReadFile(argsfile)
+	rtest.OK(t, err)
+	rtest.Equals(t, "host -o ServerAliveInterval=30 -o ServerAliveCountMax=4 -s sftp\n", string(args))
+}
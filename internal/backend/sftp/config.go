@@ -4,6 +4,7 @@ import (
 	"net/url"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/restic/restic/internal/errors"
 	"github.com/restic/restic/internal/options"
@@ -17,7 +18,13 @@ type Config struct {
 	Command string `option:"command" help:"specify command to create sftp connection"`
 	Args    string `option:"args"    help:"specify arguments for ssh"`
 
+	KeepaliveInterval time.Duration `option:"keepalive-interval" help:"send ssh keepalive messages after the connection was idle for this duration"`
+	KeepaliveMax      uint          `option:"keepalive-max"      help:"close the connection after this number of unanswered keepalive messages"`
+
 	Connections uint `option:"connections" help:"set a limit for the number of concurrent connections (default: 5)"`
+	// WriteConcurrency is the number of write requests which are sent without
+	// waiting for the server to acknowledge the previous ones.
+	WriteConcurrency uint `option:"write-concurrency" help:"number of concurrent write requests per upload, improves throughput on high-latency links (default: 1)"`
 }
 
 // NewConfig returns a new config with default options applied.
@@ -0,0 +1,138 @@
+//go:build !windows
+// +build !windows
+
+package sftp_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	sftpserver "github.com/pkg/sftp"
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/backend/sftp"
+	"github.com/restic/restic/internal/backend/test"
+	rtest "github.com/restic/restic/internal/test"
+)
+
+// serverLatencyEnv makes the test binary act as an sftp server, which delays
+// all requests by the given duration to simulate a high-latency link.
+const serverLatencyEnv = "SFTP_TEST_SERVER_LATENCY"
+
+func TestMain(m *testing.M) {
+	if latency, ok := os.LookupEnv(serverLatencyEnv); ok {
+		os.Exit(runTestServer(latency))
+	}
+	os.Exit(m.Run())
+}
+
+func runTestServer(latency string) int {
+	delay, err := time.ParseDuration(latency)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid latency: %v\n", err)
+		return 1
+	}
+
+	srv, err := sftpserver.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{delayReader(os.Stdin, delay), os.Stdout})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "unable to start server: %v\n", err)
+		return 1
+	}
+	err = srv.Serve()
+	if err != nil && err != io.EOF {
+		fmt.Fprintf(os.Stderr, "server failed: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// delayReader returns a reader which returns the data read from rd after the
+// given delay. Unlike sleeping before each read, this does not limit the
+// throughput.
+func delayReader(rd io.Reader, delay time.Duration) io.Reader {
+	type chunk struct {
+		data []byte
+		at   time.Time
+	}
+	ch := make(chan chunk, 1024)
+	go func() {
+		defer close(ch)
+		for {
+			buf := make([]byte, 64*1024)
+			n, err := rd.Read(buf)
+			if n > 0 {
+				ch <- chunk{buf[:n], time.Now().Add(delay)}
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	pr, pw := io.Pipe()
+	go func() {
+		for c := range ch {
+			time.Sleep(time.Until(c.at))
+			if _, err := pw.Write(c.data); err != nil {
+				return
+			}
+		}
+		_ = pw.Close()
+	}()
+	return pr
+}
+
+// testServerCommand returns an sftp.command which runs the test binary as sftp
+// server with the given latency.
+func testServerCommand(t testing.TB, latency time.Duration) string {
+	exe, err := os.Executable()
+	rtest.OK(t, err)
+	return fmt.Sprintf("env %v=%v %q", serverLatencyEnv, latency, exe)
+}
+
+func TestBackendSFTPConcurrentWrites(t *testing.T) {
+	suite := &test.Suite[sftp.Config]{
+		NewConfig: func() (*sftp.Config, error) {
+			cfg := sftp.NewConfig()
+			cfg.Path = rtest.TempDir(t)
+			cfg.Command = testServerCommand(t, 0)
+			cfg.WriteConcurrency = 8
+			return &cfg, nil
+		},
+		Factory: sftp.NewFactory(),
+	}
+	suite.RunTests(t)
+}
+
+func BenchmarkSaveLatency(b *testing.B) {
+	data := rtest.Random(23, 4*1024*1024)
+
+	for _, concurrency := range []uint{1, 16} {
+		b.Run(fmt.Sprintf("concurrency-%d", concurrency), func(b *testing.B) {
+			cfg := sftp.NewConfig()
+			cfg.Path = rtest.TempDir(b)
+			cfg.Command = testServerCommand(b, 5*time.Millisecond)
+			cfg.WriteConcurrency = concurrency
+
+			be, err := sftp.Create(context.TODO(), cfg)
+			rtest.OK(b, err)
+			defer func() {
+				rtest.OK(b, be.Close())
+			}()
+
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				h := backend.Handle{Type: backend.PackFile, Name: fmt.Sprintf("%064x", i)}
+				rtest.OK(b, be.Save(context.TODO(), h, backend.NewByteReader(data, be.Hasher())))
+			}
+		})
+	}
+}
@@ -49,6 +49,10 @@ func NewFactory() location.Factory {
 
 const defaultLayout = "default"
 
+// maxConcurrentRequests is the default limit of concurrent requests per file
+// of the sftp client.
+const maxConcurrentRequests = 64
+
 func startClient(cfg Config) (*SFTP, error) {
 	program, args, err := buildSSHCommand(cfg)
 	if err != nil {
@@ -101,10 +105,19 @@ func startClient(cfg Config) (*SFTP, error) {
 		}
 	}()
 
+	var opts []sftp.ClientOption
+	if cfg.WriteConcurrency > maxConcurrentRequests {
+		opts = append(opts, sftp.MaxConcurrentRequestsPerFile(int(cfg.WriteConcurrency)))
+	}
+
 	// open the SFTP session
-	client, err := sftp.NewClientPipe(rd, wr)
+	client, err := sftp.NewClientPipe(rd, wr, opts...)
 	if err != nil {
-		return nil, errors.Errorf("unable to start the sftp session, error: %v", err)
+		// the command does not speak the sftp protocol on stdin/stdout or has
+		// already exited, stop it and report its exit status
+		_ = cmd.Process.Kill()
+		_ = bg()
+		return nil, errors.Errorf("unable to start the sftp session, error: %v (%v)", err, <-ch)
 	}
 
 	err = bg()
@@ -216,6 +229,9 @@ func buildSSHCommand(cfg Config) (cmd string, args []string, err error) {
 		if cfg.Args != "" {
 			return "", nil, errors.New("cannot specify both sftp.command and sftp.args options")
 		}
+		if cfg.KeepaliveInterval != 0 || cfg.KeepaliveMax != 0 {
+			return "", nil, errors.New("cannot specify both sftp.command and sftp.keepalive-* options")
+		}
 
 		return args[0], args[1:], nil
 	}
@@ -241,6 +257,19 @@ func buildSSHCommand(cfg Config) (cmd string, args []string, err error) {
 		args = append(args, a...)
 	}
 
+	// ssh closes the connection if the server does not answer the keepalive
+	// messages, this also aborts operations which have stalled
+	if cfg.KeepaliveInterval != 0 {
+		seconds := int64((cfg.KeepaliveInterval + time.Second - 1) / time.Second)
+		if seconds < 1 {
+			return "", nil, errors.New("sftp.keepalive-interval must be positive")
+		}
+		args = append(args, "-o", fmt.Sprintf("ServerAliveInterval=%d", seconds))
+	}
+	if cfg.KeepaliveMax != 0 {
+		args = append(args, "-o", fmt.Sprintf("ServerAliveCountMax=%d", cfg.KeepaliveMax))
+	}
+
 	args = append(args, "-s", "sftp")
 	return cmd, args, nil
 }
@@ -359,7 +388,15 @@ func (r *SFTP) Save(_ context.Context, h backend.Handle, rd backend.RewindReader
 	}()
 
 	// save data, make sure to use the optimized sftp upload method
-	wbytes, err := f.ReadFrom(rd)
+	var wbytes int64
+	if r.Config.WriteConcurrency > 1 {
+		// Send multiple write requests for consecutive parts of the file
+		// without waiting for each response. After an error, parts of the
+		// file may be missing, but then the temporary file is removed anyway.
+		wbytes, err = f.ReadFromWithConcurrency(rd, int(r.Config.WriteConcurrency))
+	} else {
+		wbytes, err = f.ReadFrom(rd)
+	}
 	if err != nil {
 		_ = f.Close()
 		err = r.checkNoSpace(dirname, rd.Length(), err)
@@ -499,8 +536,9 @@ func (r *SFTP) List(ctx context.Context, t backend.FileType, fn func(backend.Fil
 		debug.Log("send %v\n", path.Base(walker.Path()))
 
 		rfi := backend.FileInfo{
-			Name: path.Base(walker.Path()),
-			Size: fi.Size(),
+			Name:    path.Base(walker.Path()),
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
 		}
 
 		if ctx.Err() != nil {
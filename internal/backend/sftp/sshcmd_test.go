@@ -3,6 +3,7 @@ package sftp
 import (
 	"reflect"
 	"testing"
+	"time"
 )
 
 var sshcmdTests = []struct {
@@ -47,6 +48,42 @@ var sshcmdTests = []struct {
 		nil,
 		"cannot specify both sftp.command and sftp.args options",
 	},
+	{
+		Config{Command: "ssh -J bastion host -s sftp"},
+		"ssh",
+		[]string{"-J", "bastion", "host", "-s", "sftp"},
+		"",
+	},
+	{
+		Config{Command: "  "},
+		"",
+		nil,
+		"command string is empty",
+	},
+	{
+		Config{User: "user", Host: "host", Path: "dir", KeepaliveInterval: 60 * time.Second, KeepaliveMax: 3},
+		"ssh",
+		[]string{"host", "-l", "user", "-o", "ServerAliveInterval=60", "-o", "ServerAliveCountMax=3", "-s", "sftp"},
+		"",
+	},
+	{
+		Config{Host: "host", Path: "dir", Args: "-i /path/to/id_rsa", KeepaliveInterval: 1500 * time.Millisecond},
+		"ssh",
+		[]string{"host", "-i", "/path/to/id_rsa", "-o", "ServerAliveInterval=2", "-s", "sftp"},
+		"",
+	},
+	{
+		Config{Host: "host", Path: "dir", KeepaliveInterval: -time.Second},
+		"",
+		nil,
+		"sftp.keepalive-interval must be positive",
+	},
+	{
+		Config{Command: "ssh something", KeepaliveMax: 3},
+		"",
+		nil,
+		"cannot specify both sftp.command and sftp.keepalive-* options",
+	},
 	{
 		// IPv6 address.
 		Config{User: "user", Host: "::1", Path: "dir"},
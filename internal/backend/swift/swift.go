@@ -213,8 +213,9 @@ func (be *beSwift) List(ctx context.Context, t backend.FileType, fn func(backend
 				}
 
 				fi := backend.FileInfo{
-					Name: m,
-					Size: obj.Bytes,
+					Name:    m,
+					Size:    obj.Bytes,
+					ModTime: obj.LastModified,
 				}
 
 				err := fn(fi)
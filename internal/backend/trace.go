@@ -0,0 +1,20 @@
+package backend
+
+import "context"
+
+type traceIDKey struct{}
+
+// WithTraceID returns a copy of ctx which carries the given trace id. Backend
+// operations called with the returned context include the trace id in their
+// debug log messages and errors, which allows correlating them with external
+// systems.
+func WithTraceID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, traceIDKey{}, id)
+}
+
+// TraceID returns the trace id stored in ctx by WithTraceID, or an empty
+// string if there is none.
+func TraceID(ctx context.Context) string {
+	id, _ := ctx.Value(traceIDKey{}).(string)
+	return id
+}
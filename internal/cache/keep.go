@@ -0,0 +1,81 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/errors"
+	"github.com/restic/restic/internal/index"
+	"github.com/restic/restic/internal/restic"
+)
+
+// SnapshotFiles returns the repository files which are required in the cache to
+// work with the snapshot sn: the snapshot file itself, the pack files which
+// contain the trees of the snapshot and the index files which reference these
+// packs. The index of repo must already be loaded.
+func SnapshotFiles(ctx context.Context, repo restic.Repository, sn *restic.Snapshot) (map[restic.FileType]restic.IDSet, error) {
+	if sn.ID() == nil {
+		return nil, errors.New("snapshot has no ID")
+	}
+	if sn.Tree == nil {
+		return nil, errors.Errorf("snapshot %v has no tree", sn.ID().Str())
+	}
+
+	blobs := restic.NewBlobSet()
+	err := restic.FindUsedBlobs(ctx, repo, restic.IDs{*sn.Tree}, blobs, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	packs := restic.NewIDSet()
+	for h := range blobs {
+		if h.Type != restic.TreeBlob {
+			continue
+		}
+		for _, pb := range repo.Index().Lookup(h) {
+			packs.Insert(pb.PackID)
+		}
+	}
+
+	indexes := restic.NewIDSet()
+	err = index.ForAllIndexes(ctx, repo, repo, func(id restic.ID, idx *index.Index, _ bool, err error) error {
+		if err != nil {
+			return err
+		}
+		for packID := range idx.Packs() {
+			if packs.Has(packID) {
+				indexes.Insert(id)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	debug.Log("snapshot %v needs %d packs and %d index files", sn.ID().Str(), len(packs), len(indexes))
+
+	return map[restic.FileType]restic.IDSet{
+		restic.SnapshotFile: restic.NewIDSet(*sn.ID()),
+		restic.PackFile:     packs,
+		restic.IndexFile:    indexes,
+	}, nil
+}
+
+// KeepOnly removes all files from the cache which are not contained in keep.
+// All cached files of a type which is missing in keep are removed.
+func (c *Cache) KeepOnly(keep map[restic.FileType]restic.IDSet) error {
+	for t := range cacheLayoutPaths {
+		valid, ok := keep[t]
+		if !ok {
+			valid = restic.NewIDSet()
+		}
+
+		if err := c.Clear(t, valid); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,56 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/cache"
+	"github.com/restic/restic/internal/repository"
+	"github.com/restic/restic/internal/restic"
+	rtest "github.com/restic/restic/internal/test"
+)
+
+func TestKeepSnapshot(t *testing.T) {
+	repo := repository.TestRepository(t).(*repository.Repository)
+	c := cache.TestNewCache(t)
+	repo.UseCache(c)
+
+	sn1 := restic.TestCreateSnapshot(t, repo, time.Unix(1500000000, 0), 2)
+	sn2 := restic.TestCreateSnapshot(t, repo, time.Unix(1600000000, 0), 2)
+	rtest.OK(t, repo.LoadIndex(context.TODO(), nil))
+
+	keep, err := cache.SnapshotFiles(context.TODO(), repo, sn1)
+	rtest.OK(t, err)
+	other, err := cache.SnapshotFiles(context.TODO(), repo, sn2)
+	rtest.OK(t, err)
+
+	for _, files := range []map[restic.FileType]restic.IDSet{keep, other} {
+		for tpe, ids := range files {
+			rtest.Assert(t, len(ids) > 0, "no files of type %v needed", tpe)
+			for id := range ids {
+				h := backend.Handle{Type: tpe, Name: id.String()}
+				rtest.Assert(t, c.Has(h), "file %v is not cached", h)
+			}
+		}
+	}
+
+	rtest.OK(t, c.KeepOnly(keep))
+
+	for tpe, ids := range keep {
+		for id := range ids {
+			h := backend.Handle{Type: tpe, Name: id.String()}
+			rtest.Assert(t, c.Has(h), "needed file %v was removed", h)
+		}
+	}
+	for tpe, ids := range other {
+		for id := range ids {
+			if keep[tpe].Has(id) {
+				continue
+			}
+			h := backend.Handle{Type: tpe, Name: id.String()}
+			rtest.Assert(t, !c.Has(h), "unrelated file %v was not removed", h)
+		}
+	}
+}
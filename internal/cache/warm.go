@@ -0,0 +1,110 @@
+package cache
+
+import (
+	"context"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/errors"
+	"github.com/restic/restic/internal/restic"
+	"github.com/restic/restic/internal/ui/progress"
+	"golang.org/x/sync/errgroup"
+)
+
+// WarmOptions selects the files which are downloaded by WarmCache.
+type WarmOptions struct {
+	Indexes   bool
+	Snapshots bool
+	// TreePacks requires that the index of the repository is already loaded.
+	TreePacks bool
+
+	// Progress is optional, it is incremented for each downloaded file.
+	Progress *progress.Counter
+}
+
+// WarmCache downloads the files selected by opts into the cache of repo, such
+// that subsequent accesses do not have to fetch them from the backend. Files
+// which are already cached are skipped. The downloads run concurrently using
+// up to repo.Connections() workers.
+func WarmCache(ctx context.Context, repo restic.Repository, opts WarmOptions) error {
+	be := backend.AsBackend[*Backend](repo.Backend())
+	if be == nil {
+		return errors.New("repository does not use a cache")
+	}
+
+	var handles []backend.Handle
+	addHandle := func(h backend.Handle) {
+		if !be.Cache.Has(h) {
+			handles = append(handles, h)
+		}
+	}
+
+	for _, t := range []struct {
+		tpe     restic.FileType
+		enabled bool
+	}{
+		{restic.IndexFile, opts.Indexes},
+		{restic.SnapshotFile, opts.Snapshots},
+	} {
+		if !t.enabled {
+			continue
+		}
+		err := repo.List(ctx, t.tpe, func(id restic.ID, _ int64) error {
+			addHandle(backend.Handle{Type: t.tpe, Name: id.String()})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	if opts.TreePacks {
+		packs := restic.NewIDSet()
+		err := repo.Index().Each(ctx, func(pb restic.PackedBlob) {
+			if pb.Type == restic.TreeBlob {
+				packs.Insert(pb.PackID)
+			}
+		})
+		if err != nil {
+			return err
+		}
+		for id := range packs {
+			addHandle(backend.Handle{Type: restic.PackFile, IsMetadata: true, Name: id.String()})
+		}
+	}
+
+	debug.Log("warming cache with %d files", len(handles))
+	if opts.Progress != nil {
+		opts.Progress.SetMax(uint64(len(handles)))
+	}
+
+	wg, ctx := errgroup.WithContext(ctx)
+	ch := make(chan backend.Handle)
+	wg.Go(func() error {
+		defer close(ch)
+		for _, h := range handles {
+			select {
+			case ch <- h:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		return nil
+	})
+
+	for i := 0; i < int(repo.Connections()); i++ {
+		wg.Go(func() error {
+			for h := range ch {
+				if err := be.cacheFile(ctx, h); err != nil {
+					return errors.Wrapf(err, "cache %v", h)
+				}
+				if opts.Progress != nil {
+					opts.Progress.Add(1)
+				}
+			}
+			return nil
+		})
+	}
+
+	return wg.Wait()
+}
@@ -0,0 +1,82 @@
+package cache_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/cache"
+	"github.com/restic/restic/internal/repository"
+	"github.com/restic/restic/internal/restic"
+	rtest "github.com/restic/restic/internal/test"
+	"github.com/restic/restic/internal/ui/progress"
+)
+
+func TestWarmCache(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		opts cache.WarmOptions
+	}{
+		{"indexes", cache.WarmOptions{Indexes: true}},
+		{"snapshots", cache.WarmOptions{Snapshots: true}},
+		{"treepacks", cache.WarmOptions{TreePacks: true}},
+		{"all", cache.WarmOptions{Indexes: true, Snapshots: true, TreePacks: true}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			repo := repository.TestRepository(t).(*repository.Repository)
+			c := cache.TestNewCache(t)
+			repo.UseCache(c)
+
+			restic.TestCreateSnapshot(t, repo, time.Unix(1500000000, 0), 2)
+			restic.TestCreateSnapshot(t, repo, time.Unix(1600000000, 0), 2)
+			rtest.OK(t, repo.LoadIndex(context.TODO(), nil))
+
+			expected := map[restic.FileType]restic.IDSet{
+				restic.IndexFile:    restic.NewIDSet(),
+				restic.SnapshotFile: restic.NewIDSet(),
+				restic.PackFile:     restic.NewIDSet(),
+			}
+			for _, tpe := range []restic.FileType{restic.IndexFile, restic.SnapshotFile} {
+				rtest.OK(t, repo.List(context.TODO(), tpe, func(id restic.ID, _ int64) error {
+					expected[tpe].Insert(id)
+					return nil
+				}))
+			}
+			rtest.OK(t, repo.Index().Each(context.TODO(), func(pb restic.PackedBlob) {
+				if pb.Type == restic.TreeBlob {
+					expected[restic.PackFile].Insert(pb.PackID)
+				}
+			}))
+
+			// start with an empty cache
+			rtest.OK(t, c.KeepOnly(nil))
+
+			counter := progress.NewCounter(time.Hour, 0, func(uint64, uint64, time.Duration, bool) {})
+			opts := test.opts
+			opts.Progress = counter
+			rtest.OK(t, cache.WarmCache(context.TODO(), repo, opts))
+			counter.Done()
+
+			selected := map[restic.FileType]bool{
+				restic.IndexFile:    opts.Indexes,
+				restic.SnapshotFile: opts.Snapshots,
+				restic.PackFile:     opts.TreePacks,
+			}
+			count := 0
+			for tpe, ids := range expected {
+				rtest.Assert(t, len(ids) > 0, "no files of type %v", tpe)
+				for id := range ids {
+					h := backend.Handle{Type: tpe, Name: id.String()}
+					rtest.Equals(t, selected[tpe], c.Has(h))
+					if selected[tpe] {
+						count++
+					}
+				}
+			}
+			value, max := counter.Get()
+			rtest.Equals(t, uint64(count), value)
+			rtest.Equals(t, uint64(count), max)
+		})
+	}
+}
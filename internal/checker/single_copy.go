@@ -0,0 +1,75 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/restic"
+)
+
+// SingleCopyTree is a tree blob which is referenced by many snapshots, but is
+// only stored in a single pack file. Losing this pack file would damage all
+// these snapshots.
+type SingleCopyTree struct {
+	ID        restic.ID
+	PackID    restic.ID
+	Snapshots int
+}
+
+func (e *SingleCopyTree) Error() string {
+	return fmt.Sprintf("tree %v is referenced by %d snapshots, but only stored in pack %v",
+		e.ID.Str(), e.Snapshots, e.PackID.Str())
+}
+
+// SingleCopyTrees returns all tree blobs which are referenced by at least
+// minSnapshots snapshots and for which the index contains only a single copy.
+// restic does not store blobs redundantly and relies on the backend to keep
+// the data safe, this function identifies the pack files whose loss would
+// affect the most snapshots. The trees of each snapshot are traversed
+// separately, thus this is considerably slower than Structure. The index and
+// the snapshots must already be loaded. The result is sorted by the number of
+// referencing snapshots in descending order.
+func (c *Checker) SingleCopyTrees(ctx context.Context, minSnapshots int) ([]*SingleCopyTree, error) {
+	trees, errs := loadSnapshotTreeIDs(ctx, c.snapshots, c.repo)
+	if len(errs) > 0 {
+		return nil, errs[0]
+	}
+
+	refs := make(map[restic.ID]int)
+	for _, tree := range trees {
+		blobs := restic.NewBlobSet()
+		err := restic.FindUsedBlobs(ctx, c.repo, restic.IDs{tree}, blobs, nil)
+		if err != nil {
+			return nil, err
+		}
+		for h := range blobs {
+			if h.Type == restic.TreeBlob {
+				refs[h.ID]++
+			}
+		}
+	}
+
+	var result []*SingleCopyTree
+	for id, count := range refs {
+		if count < minSnapshots {
+			continue
+		}
+		pbs := c.masterIndex.Lookup(restic.BlobHandle{ID: id, Type: restic.TreeBlob})
+		if len(pbs) != 1 {
+			continue
+		}
+		result = append(result, &SingleCopyTree{ID: id, PackID: pbs[0].PackID, Snapshots: count})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Snapshots != result[j].Snapshots {
+			return result[i].Snapshots > result[j].Snapshots
+		}
+		return result[i].ID.String() < result[j].ID.String()
+	})
+
+	debug.Log("found %d single copy trees referenced by at least %d snapshots", len(result), minSnapshots)
+	return result, nil
+}
@@ -0,0 +1,73 @@
+package checker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/restic/restic/internal/checker"
+	"github.com/restic/restic/internal/repository"
+	"github.com/restic/restic/internal/restic"
+	"github.com/restic/restic/internal/test"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestSingleCopyTrees(t *testing.T) {
+	repo := repository.TestRepository(t)
+
+	// snapshots created with the same seed share all trees
+	at := time.Unix(1500000000, 0)
+	var snapshots []*restic.Snapshot
+	for i := 0; i < 3; i++ {
+		snapshots = append(snapshots, restic.TestCreateSnapshot(t, repo, at.Add(time.Duration(i)*time.Millisecond), 2))
+	}
+	restic.TestCreateSnapshot(t, repo, at.Add(time.Hour), 2)
+
+	chkr := checker.New(repo, false)
+	_, errs := chkr.LoadIndex(context.TODO(), nil)
+	test.OKs(t, errs)
+	test.OK(t, chkr.LoadSnapshots(context.TODO()))
+
+	hot, err := chkr.SingleCopyTrees(context.TODO(), 3)
+	test.OK(t, err)
+	test.Assert(t, len(hot) > 0, "expected warnings for single copy trees")
+
+	root := *snapshots[0].Tree
+	var rootWarning *checker.SingleCopyTree
+	for _, w := range hot {
+		test.Equals(t, 3, w.Snapshots)
+		if w.ID == root {
+			rootWarning = w
+		}
+	}
+	test.Assert(t, rootWarning != nil, "no warning for shared root tree %v", root.Str())
+	pbs := repo.Index().Lookup(restic.BlobHandle{ID: root, Type: restic.TreeBlob})
+	test.Equals(t, 1, len(pbs))
+	test.Equals(t, pbs[0].PackID, rootWarning.PackID)
+
+	// trees only referenced by a single snapshot are not reported
+	none, err := chkr.SingleCopyTrees(context.TODO(), 4)
+	test.OK(t, err)
+	test.Equals(t, 0, len(none))
+
+	// store a second copy of the root tree
+	buf, err := repo.LoadBlob(context.TODO(), restic.TreeBlob, root, nil)
+	test.OK(t, err)
+	var wg errgroup.Group
+	repo.StartPackUploader(context.TODO(), &wg)
+	_, _, _, err = repo.SaveBlob(context.TODO(), restic.TreeBlob, buf, root, true)
+	test.OK(t, err)
+	test.OK(t, repo.Flush(context.TODO()))
+
+	chkr = checker.New(repo, false)
+	_, errs = chkr.LoadIndex(context.TODO(), nil)
+	test.OKs(t, errs)
+	test.OK(t, chkr.LoadSnapshots(context.TODO()))
+
+	hot2, err := chkr.SingleCopyTrees(context.TODO(), 3)
+	test.OK(t, err)
+	test.Equals(t, len(hot)-1, len(hot2))
+	for _, w := range hot2 {
+		test.Assert(t, w.ID != root, "tree %v with two copies was reported", root.Str())
+	}
+}
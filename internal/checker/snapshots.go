@@ -0,0 +1,105 @@
+package checker
+
+import (
+	"context"
+
+	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/errors"
+	"github.com/restic/restic/internal/restic"
+	"golang.org/x/sync/errgroup"
+)
+
+// CheckSnapshots verifies that the trees of the given snapshots can be loaded
+// and that all blobs they reference are contained in the index. Up to
+// concurrency snapshots are checked in parallel. The returned map contains the
+// errors found for each snapshot, snapshots without errors are omitted. The
+// index of repo must already be loaded. The error is only non-nil if the check
+// itself failed, for example because ctx was cancelled.
+func CheckSnapshots(ctx context.Context, repo restic.Repository, ids []restic.ID, concurrency int) (map[restic.ID][]error, error) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	// checkTree only requires the repository
+	c := &Checker{repo: repo}
+
+	results := make([][]error, len(ids))
+	wg, ctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, concurrency)
+	for i, id := range ids {
+		i, id := i, id
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			if err := wg.Wait(); err != nil {
+				return nil, err
+			}
+			return nil, ctx.Err()
+		}
+		wg.Go(func() error {
+			defer func() { <-sem }()
+			errs, err := c.checkSnapshot(ctx, id)
+			results[i] = errs
+			return err
+		})
+	}
+	if err := wg.Wait(); err != nil {
+		return nil, err
+	}
+
+	found := make(map[restic.ID][]error)
+	for i, id := range ids {
+		if len(results[i]) > 0 {
+			found[id] = append(found[id], results[i]...)
+		}
+	}
+	return found, nil
+}
+
+// checkSnapshot checks all trees of the snapshot id and returns the errors
+// found. The returned error is only set if ctx was cancelled.
+func (c *Checker) checkSnapshot(ctx context.Context, id restic.ID) (errs []error, err error) {
+	sn, err := restic.LoadSnapshot(ctx, c.repo, id)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return []error{errors.Wrapf(err, "load snapshot %v", id.Str())}, nil
+	}
+	if sn.Tree == nil {
+		return []error{errors.Errorf("snapshot %v has no tree", id.Str())}, nil
+	}
+
+	debug.Log("checking trees of snapshot %v", id.Str())
+	visited := restic.NewIDSet()
+	queue := restic.IDs{*sn.Tree}
+	for len(queue) > 0 {
+		treeID := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if visited.Has(treeID) {
+			continue
+		}
+		visited.Insert(treeID)
+
+		tree, err := restic.LoadTree(ctx, c.repo, treeID)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil {
+			errs = append(errs, &TreeError{ID: treeID, Errors: []error{err}})
+			continue
+		}
+
+		if treeErrs := c.checkTree(treeID, tree); len(treeErrs) > 0 {
+			errs = append(errs, &TreeError{ID: treeID, Errors: treeErrs})
+		}
+
+		for _, node := range tree.Nodes {
+			if node.Type == "dir" && node.Subtree != nil && !node.Subtree.IsNull() {
+				queue = append(queue, *node.Subtree)
+			}
+		}
+	}
+
+	return errs, nil
+}
@@ -0,0 +1,61 @@
+package checker_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/restic/restic/internal/checker"
+	"github.com/restic/restic/internal/repository"
+	"github.com/restic/restic/internal/restic"
+	"github.com/restic/restic/internal/test"
+	"golang.org/x/sync/errgroup"
+)
+
+func TestCheckSnapshots(t *testing.T) {
+	repo := repository.TestRepository(t)
+
+	var ids []restic.ID
+	for i := 0; i < 4; i++ {
+		sn := restic.TestCreateSnapshot(t, repo, time.Unix(int64(1500000000+i*3600), 0), 2)
+		ids = append(ids, *sn.ID())
+	}
+
+	// create a snapshot whose tree references a missing data blob
+	var wg errgroup.Group
+	repo.StartPackUploader(context.TODO(), &wg)
+	missing := restic.NewRandomID()
+	tree := restic.NewTree(1)
+	test.OK(t, tree.Insert(&restic.Node{Name: "broken", Type: "file", Content: restic.IDs{missing}}))
+	treeID, err := restic.SaveTree(context.TODO(), repo, tree)
+	test.OK(t, err)
+	test.OK(t, repo.Flush(context.TODO()))
+
+	sn, err := restic.NewSnapshot([]string{"/broken"}, nil, "foo", time.Unix(1600000000, 0))
+	test.OK(t, err)
+	sn.Tree = &treeID
+	brokenID, err := restic.SaveSnapshot(context.TODO(), repo, sn)
+	test.OK(t, err)
+	ids = append(ids, brokenID)
+
+	test.OK(t, repo.LoadIndex(context.TODO(), nil))
+
+	errs, err := checker.CheckSnapshots(context.TODO(), repo, ids, 3)
+	test.OK(t, err)
+	test.Equals(t, 1, len(errs))
+	test.Assert(t, len(errs[brokenID]) == 1, "expected one error for broken snapshot, got %v", errs[brokenID])
+	treeErr, ok := errs[brokenID][0].(*checker.TreeError)
+	test.Assert(t, ok, "unexpected error type %T", errs[brokenID][0])
+	test.Equals(t, treeID, treeErr.ID)
+
+	// only checking the intact snapshots reports no errors
+	errs, err = checker.CheckSnapshots(context.TODO(), repo, ids[:4], 2)
+	test.OK(t, err)
+	test.Equals(t, 0, len(errs))
+
+	// a missing snapshot is reported as well
+	unknown := restic.NewRandomID()
+	errs, err = checker.CheckSnapshots(context.TODO(), repo, restic.IDs{unknown}, 1)
+	test.OK(t, err)
+	test.Equals(t, 1, len(errs[unknown]))
+}
@@ -0,0 +1,67 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"sort"
+
+	"github.com/restic/restic/internal/errors"
+	"github.com/restic/restic/internal/restic"
+)
+
+// BlobShard returns the shard out of totalShards to which the blob id is
+// assigned. As blob IDs are hashes, the blobs are spread evenly across all
+// shards.
+func BlobShard(id restic.ID, totalShards int) int {
+	return int(binary.LittleEndian.Uint64(id[:8]) % uint64(totalShards))
+}
+
+// IterateBlobShard calls fn for all index entries of the repository which
+// belong to the given shard out of totalShards. Each blob is assigned to
+// exactly one shard based on its ID, thus workers which each iterate over a
+// different shard handle disjoint sets of blobs which together cover the whole
+// index. The entries are passed to fn sorted by blob handle and pack ID, such
+// that the iteration order is stable as long as the index does not change. If
+// fn returns an error, the iteration is aborted and that error is returned.
+func IterateBlobShard(ctx context.Context, repo restic.Repository, shard, totalShards int, fn func(restic.PackedBlob) error) error {
+	if totalShards <= 0 {
+		return errors.Errorf("invalid number of shards %d", totalShards)
+	}
+	if shard < 0 || shard >= totalShards {
+		return errors.Errorf("shard %d out of range [0, %d)", shard, totalShards)
+	}
+
+	var blobs []restic.PackedBlob
+	err := repo.Index().Each(ctx, func(pb restic.PackedBlob) {
+		if BlobShard(pb.ID, totalShards) == shard {
+			blobs = append(blobs, pb)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.Slice(blobs, func(i, j int) bool {
+		if c := bytes.Compare(blobs[i].ID[:], blobs[j].ID[:]); c != 0 {
+			return c < 0
+		}
+		if blobs[i].Type != blobs[j].Type {
+			return blobs[i].Type < blobs[j].Type
+		}
+		if c := bytes.Compare(blobs[i].PackID[:], blobs[j].PackID[:]); c != 0 {
+			return c < 0
+		}
+		return blobs[i].Offset < blobs[j].Offset
+	})
+
+	for _, pb := range blobs {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := fn(pb); err != nil {
+			return err
+		}
+	}
+	return nil
+}
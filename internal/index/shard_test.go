@@ -0,0 +1,62 @@
+package index_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/restic/restic/internal/index"
+	"github.com/restic/restic/internal/restic"
+	rtest "github.com/restic/restic/internal/test"
+)
+
+func TestIterateBlobShard(t *testing.T) {
+	repo := createFilledRepo(t, 3, restic.StableRepoVersion)
+	rtest.OK(t, repo.LoadIndex(context.TODO(), nil))
+
+	all := make(map[restic.PackedBlob]int)
+	rtest.OK(t, repo.Index().Each(context.TODO(), func(pb restic.PackedBlob) {
+		all[pb]++
+	}))
+	rtest.Assert(t, len(all) > 0, "repository contains no blobs")
+
+	const totalShards = 7
+	seen := make(map[restic.PackedBlob]int)
+	for shard := 0; shard < totalShards; shard++ {
+		var first []restic.PackedBlob
+		rtest.OK(t, index.IterateBlobShard(context.TODO(), repo, shard, totalShards, func(pb restic.PackedBlob) error {
+			rtest.Equals(t, shard, index.BlobShard(pb.ID, totalShards))
+			seen[pb]++
+			first = append(first, pb)
+			return nil
+		}))
+
+		// iterating the same shard again must yield the same order
+		var second []restic.PackedBlob
+		rtest.OK(t, index.IterateBlobShard(context.TODO(), repo, shard, totalShards, func(pb restic.PackedBlob) error {
+			second = append(second, pb)
+			return nil
+		}))
+		rtest.Equals(t, first, second)
+	}
+
+	for pb, count := range seen {
+		rtest.Assert(t, count == 1, "blob %v was returned %d times", pb, count)
+		rtest.Assert(t, all[pb] == 1, "blob %v is not contained in the index", pb)
+	}
+	rtest.Equals(t, len(all), len(seen))
+}
+
+func TestIterateBlobShardInvalid(t *testing.T) {
+	repo := createFilledRepo(t, 1, restic.StableRepoVersion)
+	rtest.OK(t, repo.LoadIndex(context.TODO(), nil))
+
+	fn := func(restic.PackedBlob) error { return nil }
+	for _, test := range []struct{ shard, total int }{
+		{0, 0},
+		{-1, 3},
+		{3, 3},
+	} {
+		err := index.IterateBlobShard(context.TODO(), repo, test.shard, test.total, fn)
+		rtest.Assert(t, err != nil, "expected error for shard %d of %d", test.shard, test.total)
+	}
+}
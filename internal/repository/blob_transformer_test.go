@@ -0,0 +1,160 @@
+package repository_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/restic/restic/internal/checker"
+	"github.com/restic/restic/internal/errors"
+	"github.com/restic/restic/internal/repository"
+	"github.com/restic/restic/internal/restic"
+	rtest "github.com/restic/restic/internal/test"
+	"golang.org/x/sync/errgroup"
+)
+
+type identityTransformer struct{}
+
+func (identityTransformer) Transform(_ restic.BlobHandle, buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+func (identityTransformer) Reverse(_ restic.BlobHandle, buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+type xorTransformer struct {
+	key byte
+}
+
+func (tf xorTransformer) xor(buf []byte) []byte {
+	out := make([]byte, len(buf))
+	for i := range buf {
+		out[i] = buf[i] ^ tf.key
+	}
+	return out
+}
+
+func (tf xorTransformer) Transform(_ restic.BlobHandle, buf []byte) ([]byte, error) {
+	return tf.xor(buf), nil
+}
+
+func (tf xorTransformer) Reverse(_ restic.BlobHandle, buf []byte) ([]byte, error) {
+	return tf.xor(buf), nil
+}
+
+// trailerTransformer appends a trailer to each blob.
+type trailerTransformer struct{}
+
+var trailer = []byte("trailer")
+
+func (trailerTransformer) Transform(_ restic.BlobHandle, buf []byte) ([]byte, error) {
+	return append(append([]byte{}, buf...), trailer...), nil
+}
+
+func (trailerTransformer) Reverse(_ restic.BlobHandle, buf []byte) ([]byte, error) {
+	if !bytes.HasSuffix(buf, trailer) {
+		return nil, errors.New("trailer missing")
+	}
+	return buf[:len(buf)-len(trailer)], nil
+}
+
+// brokenTransformer cannot reverse its transformation.
+type brokenTransformer struct {
+	xorTransformer
+}
+
+func (brokenTransformer) Reverse(_ restic.BlobHandle, buf []byte) ([]byte, error) {
+	return buf, nil
+}
+
+func saveTestBlobs(t *testing.T, repo restic.Repository) map[restic.ID][]byte {
+	var wg errgroup.Group
+	repo.StartPackUploader(context.TODO(), &wg)
+
+	blobs := make(map[restic.ID][]byte)
+	for _, size := range testSizes {
+		data := make([]byte, size)
+		_, err := io.ReadFull(rnd, data)
+		rtest.OK(t, err)
+
+		id, _, _, err := repo.SaveBlob(context.TODO(), restic.DataBlob, data, restic.ID{}, false)
+		rtest.OK(t, err)
+		blobs[id] = data
+	}
+	rtest.OK(t, repo.Flush(context.TODO()))
+	return blobs
+}
+
+func testBlobTransformerRoundTrip(t *testing.T, version uint, tf restic.BlobTransformer) {
+	be := repository.TestBackend(t)
+	repo := repository.TestRepositoryWithBackend(t, be, version, repository.Options{BlobTransformer: tf})
+	blobs := saveTestBlobs(t, repo)
+
+	for id, data := range blobs {
+		buf, err := repo.LoadBlob(context.TODO(), restic.DataBlob, id, nil)
+		rtest.OK(t, err)
+		rtest.Assert(t, bytes.Equal(buf, data), "data for blob %v does not match", id.Str())
+	}
+
+	// streaming the packs and checking the repository must reverse the transformation as well
+	checker.TestCheckRepo(t, repo, true)
+}
+
+func TestBlobTransformerRoundTrip(t *testing.T) {
+	for _, test := range []struct {
+		name string
+		tf   restic.BlobTransformer
+	}{
+		{"identity", identityTransformer{}},
+		{"xor", xorTransformer{key: 0x5a}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			repository.TestAllVersions(t, func(t *testing.T, version uint) {
+				testBlobTransformerRoundTrip(t, version, test.tf)
+			})
+		})
+	}
+
+	// changing the length is only supported for compressed blobs
+	t.Run("trailer", func(t *testing.T) {
+		testBlobTransformerRoundTrip(t, 2, trailerTransformer{})
+	})
+}
+
+func TestBlobTransformerStorage(t *testing.T) {
+	be := repository.TestBackend(t)
+	repo := repository.TestRepositoryWithBackend(t, be, 0, repository.Options{BlobTransformer: xorTransformer{key: 0x5a}})
+	blobs := saveTestBlobs(t, repo)
+
+	// without the transformer the stored blobs cannot be decrypted
+	repo = repository.TestOpenBackend(t, be)
+	rtest.OK(t, repo.LoadIndex(context.TODO(), nil))
+	for id := range blobs {
+		_, err := repo.LoadBlob(context.TODO(), restic.DataBlob, id, nil)
+		rtest.Assert(t, err != nil, "loading blob %v without transformer succeeded", id.Str())
+	}
+}
+
+func TestBlobTransformerErrors(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		version uint
+		tf      restic.BlobTransformer
+		err     string
+	}{
+		{"not reversible", 2, brokenTransformer{xorTransformer{key: 0x5a}}, "is not reversible"},
+		{"length changed", 1, trailerTransformer{}, "length of uncompressed blob changed"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			repo := repository.TestRepositoryWithBackend(t, nil, test.version, repository.Options{BlobTransformer: test.tf})
+			var wg errgroup.Group
+			repo.StartPackUploader(context.TODO(), &wg)
+
+			_, _, _, err := repo.SaveBlob(context.TODO(), restic.DataBlob, []byte("foobar"), restic.ID{}, false)
+			rtest.Assert(t, err != nil && strings.Contains(err.Error(), test.err), "unexpected error %v", err)
+		})
+	}
+}
@@ -16,6 +16,44 @@ type lockContext struct {
 	lock      *restic.Lock
 	cancel    context.CancelFunc
 	refreshWG sync.WaitGroup
+
+	noteMu sync.Mutex
+	note   func() string
+}
+
+// updateNote stores the current note in the lock, such that it is included
+// when the lock is refreshed.
+func (l *lockContext) updateNote() {
+	l.noteMu.Lock()
+	note := l.note
+	l.noteMu.Unlock()
+
+	if note != nil {
+		l.lock.SetNote(note())
+	}
+}
+
+type lockContextKey struct{}
+
+// SetLockNote sets a function which returns a short description of the
+// progress of the current operation, for example "prune: repacking 40%". The
+// function is called whenever the lock is refreshed and the result is stored
+// in the lock file, such that other processes can see that the lock holder is
+// still alive and how far it has progressed. ctx must be the context returned
+// by Lock or derived from it, otherwise SetLockNote does nothing. Pass nil to
+// clear the note.
+func SetLockNote(ctx context.Context, note func() string) {
+	lockInfo, ok := ctx.Value(lockContextKey{}).(*lockContext)
+	if !ok {
+		return
+	}
+
+	lockInfo.noteMu.Lock()
+	lockInfo.note = note
+	lockInfo.noteMu.Unlock()
+	if note == nil {
+		lockInfo.lock.SetNote("")
+	}
 }
 
 type locker struct {
@@ -98,6 +136,7 @@ retryLoop:
 		lock:   lock,
 		cancel: cancel,
 	}
+	ctx = context.WithValue(ctx, lockContextKey{}, lockInfo)
 	lockInfo.refreshWG.Add(2)
 	refreshChan := make(chan struct{})
 	forceRefreshChan := make(chan refreshLockRequest)
@@ -148,6 +187,7 @@ func (l *locker) refreshLocks(ctx context.Context, backend backend.Backend, lock
 
 		case req := <-forceRefresh:
 			debug.Log("trying to refresh stale lock")
+			lockInfo.updateNote()
 			// keep on going if our current lock still exists
 			success := tryRefreshStaleLock(ctx, backend, lock, lockInfo.cancel, logger)
 			// inform refresh goroutine about forced refresh
@@ -168,6 +208,7 @@ func (l *locker) refreshLocks(ctx context.Context, backend backend.Backend, lock
 			}
 
 			debug.Log("refreshing locks")
+			lockInfo.updateNote()
 			err := lock.Refresh(context.TODO())
 			if err != nil {
 				logger("unable to refresh lock: %v\n", err)
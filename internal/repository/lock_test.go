@@ -173,6 +173,58 @@ func TestLockSuccessfulRefresh(t *testing.T) {
 	lock.Unlock()
 }
 
+func TestLockRefreshNote(t *testing.T) {
+	t.Parallel()
+	repo := openLockTestRepo(t, nil)
+
+	li := &locker{
+		retrySleepStart:       lockerInst.retrySleepStart,
+		retrySleepMax:         lockerInst.retrySleepMax,
+		refreshInterval:       20 * time.Millisecond,
+		refreshabilityTimeout: time.Second,
+	}
+	lock, wrappedCtx := checkedLockRepo(context.Background(), t, repo, li, 0)
+	defer lock.Unlock()
+
+	var m sync.Mutex
+	progress := 0
+	SetLockNote(wrappedCtx, func() string {
+		m.Lock()
+		defer m.Unlock()
+		return fmt.Sprintf("test: %d%%", progress)
+	})
+
+	// readNote waits until the lock file contains the expected note
+	readNote := func(expected string) {
+		var note string
+		for start := time.Now(); time.Since(start) < 5*time.Second; time.Sleep(10 * time.Millisecond) {
+			err := restic.ForAllLocks(context.TODO(), repo, nil, func(_ restic.ID, lock *restic.Lock, err error) error {
+				if err == nil {
+					note = lock.Note
+				}
+				return nil
+			})
+			rtest.OK(t, err)
+			if note == expected {
+				return
+			}
+		}
+		t.Fatalf("lock note not updated, expected %q, got %q", expected, note)
+	}
+
+	readNote("test: 0%")
+	m.Lock()
+	progress = 40
+	m.Unlock()
+	readNote("test: 40%")
+
+	SetLockNote(wrappedCtx, nil)
+	readNote("")
+
+	// contexts without a lock are ignored
+	SetLockNote(context.Background(), func() string { return "unused" })
+}
+
 type slowBackend struct {
 	backend.Backend
 	m     sync.Mutex
@@ -37,6 +37,9 @@ type packerManager struct {
 	pm       sync.Mutex
 	packer   *Packer
 	packSize uint
+	// packSizeFn is optional, if set it is called to update the target pack
+	// size whenever a new pack is started.
+	packSizeFn func() uint
 }
 
 // newPackerManager returns a new packer manager which writes temporary files
@@ -71,6 +74,9 @@ func (r *packerManager) SaveBlob(ctx context.Context, t restic.BlobType, id rest
 
 	var err error
 	packer := r.packer
+	if r.packer == nil && r.packSizeFn != nil {
+		r.packSize = r.packSizeFn()
+	}
 	// use separate packer if compressed length is larger than the packsize
 	// this speeds up the garbage collection of oversized blobs and reduces the cache size
 	// as the oversize blobs are only downloaded if necessary
@@ -181,6 +187,7 @@ func (r *Repository) savePacker(ctx context.Context, t restic.BlobType, p *Packe
 	}
 
 	debug.Log("saved as %v", h)
+	r.estimatedSize.Add(uint64(p.Packer.Size()))
 
 	err = p.tmpfile.Close()
 	if err != nil {
@@ -107,6 +107,32 @@ func TestPackerManagerWithOversizeBlob(t *testing.T) {
 	test.Equals(t, packFiles, 2)
 }
 
+func TestPackerManagerAutoPackSize(t *testing.T) {
+	// simulate a repository which doubles in size with every uploaded pack
+	repoSize := uint64(32 * 1024 * 1024 * 1024)
+	var packSizes []uint
+	pm := newPackerManager(crypto.NewRandomKey(), restic.DataBlob, DefaultPackSize, func(ctx context.Context, tp restic.BlobType, p *Packer) error {
+		packSizes = append(packSizes, p.Size())
+		repoSize *= 2
+		return nil
+	})
+	pm.packSizeFn = func() uint {
+		return autoPackSize(repoSize)
+	}
+
+	buf := make([]byte, 1024*1024)
+	for len(packSizes) < 4 {
+		_, err := pm.SaveBlob(context.TODO(), restic.DataBlob, restic.ID{}, buf, 0)
+		test.OK(t, err)
+	}
+	test.OK(t, pm.Flush(context.TODO()))
+
+	test.Assert(t, packSizes[0] >= MinPackSize, "first pack too small: %v", packSizes[0])
+	for i := 1; i < len(packSizes); i++ {
+		test.Assert(t, packSizes[i] > packSizes[i-1], "pack size did not grow with repository size: %v", packSizes)
+	}
+}
+
 func BenchmarkPackerManager(t *testing.B) {
 	// Run testPackerManager if it hasn't run already, to set totalSize.
 	once.Do(func() {
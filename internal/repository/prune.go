@@ -5,7 +5,10 @@ import (
 	"fmt"
 	"math"
 	"sort"
+	"time"
 
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/debug"
 	"github.com/restic/restic/internal/errors"
 	"github.com/restic/restic/internal/index"
 	"github.com/restic/restic/internal/pack"
@@ -28,6 +31,10 @@ type PruneOptions struct {
 	RepackCachableOnly bool
 	RepackSmall        bool
 	RepackUncompressed bool
+	// RepackOlderThan selects all packs which were stored longer ago than the
+	// given duration for repacking, even if they are fully used. Packs for
+	// which the backend does not report a modification time are not affected.
+	RepackOlderThan time.Duration
 }
 
 type PruneStats struct {
@@ -85,6 +92,7 @@ type packInfoWithID struct {
 	ID restic.ID
 	packInfo
 	mustCompress bool
+	tooOld       bool
 }
 
 // PlanPrune selects which files to rewrite and which to delete and which blobs to keep.
@@ -329,10 +337,22 @@ func decidePackAction(ctx context.Context, opts PruneOptions, repo restic.Reposi
 		targetPackSize = repo.PackSize() / 5 * 4
 	}
 
+	var repackCutoff time.Time
+	if opts.RepackOlderThan > 0 {
+		repackCutoff = time.Now().Add(-opts.RepackOlderThan)
+	}
+
 	// loop over all packs and decide what to do
 	bar := printer.NewCounter("packs processed")
 	bar.SetMax(uint64(len(indexPack)))
-	err := repo.List(ctx, restic.PackFile, func(id restic.ID, packSize int64) error {
+	err := repo.Backend().List(ctx, restic.PackFile, func(fi backend.FileInfo) error {
+		id, err := restic.ParseID(fi.Name)
+		if err != nil {
+			debug.Log("unable to parse %v as an ID", fi.Name)
+			return nil
+		}
+		packSize := fi.Size
+
 		p, ok := indexPack[id]
 		if !ok {
 			// Pack was not referenced in index and is not used  => immediately remove!
@@ -370,6 +390,7 @@ func decidePackAction(ctx context.Context, opts PruneOptions, repo restic.Reposi
 			// compress data blobs if requested
 			mustCompress = (p.tpe == restic.TreeBlob || opts.RepackUncompressed) && p.uncompressed
 		}
+		tooOld := !repackCutoff.IsZero() && !fi.ModTime.IsZero() && fi.ModTime.Before(repackCutoff)
 
 		// decide what to do
 		switch {
@@ -383,7 +404,7 @@ func decidePackAction(ctx context.Context, opts PruneOptions, repo restic.Reposi
 			// if this is a data pack and --repack-cacheable-only is set => keep pack!
 			stats.Packs.Keep++
 
-		case p.unusedBlobs == 0 && p.tpe != restic.InvalidBlob && !mustCompress:
+		case p.unusedBlobs == 0 && p.tpe != restic.InvalidBlob && !mustCompress && !tooOld:
 			if packSize >= int64(targetPackSize) {
 				// All blobs in pack are used and not mixed => keep pack!
 				stats.Packs.Keep++
@@ -393,7 +414,7 @@ func decidePackAction(ctx context.Context, opts PruneOptions, repo restic.Reposi
 
 		default:
 			// all other packs are candidates for repacking
-			repackCandidates = append(repackCandidates, packInfoWithID{ID: id, packInfo: p, mustCompress: mustCompress})
+			repackCandidates = append(repackCandidates, packInfoWithID{ID: id, packInfo: p, mustCompress: mustCompress, tooOld: tooOld})
 		}
 
 		delete(indexPack, id)
@@ -484,8 +505,8 @@ func decidePackAction(ctx context.Context, opts PruneOptions, repo restic.Reposi
 		case reachedRepackSize:
 			stats.Packs.Keep++
 
-		case p.tpe != restic.DataBlob, p.mustCompress:
-			// repacking non-data packs / uncompressed-trees is only limited by repackSize
+		case p.tpe != restic.DataBlob, p.mustCompress, p.tooOld:
+			// repacking non-data packs / uncompressed-trees / old packs is only limited by repackSize
 			repack(p.ID, p.packInfo)
 
 		case reachedUnusedSizeAfter && packIsLargeEnough:
@@ -539,9 +560,15 @@ func (plan *PrunePlan) Execute(ctx context.Context, printer progress.Printer) (e
 	// make sure the plan can only be used once
 	plan.repo = nil
 
+	setNote := func(note string) {
+		SetLockNote(ctx, func() string { return note })
+	}
+	defer SetLockNote(ctx, nil)
+
 	// unreferenced packs can be safely deleted first
 	if len(plan.removePacksFirst) != 0 {
 		printer.P("deleting unreferenced packs\n")
+		setNote("prune: deleting unreferenced packs")
 		_ = deleteFiles(ctx, true, repo, plan.removePacksFirst, restic.PackFile, printer)
 	}
 	if ctx.Err() != nil {
@@ -552,6 +579,13 @@ func (plan *PrunePlan) Execute(ctx context.Context, printer progress.Printer) (e
 		printer.P("repacking packs\n")
 		bar := printer.NewCounter("packs repacked")
 		bar.SetMax(uint64(len(plan.repackPacks)))
+		SetLockNote(ctx, func() string {
+			if bar == nil {
+				return fmt.Sprintf("prune: repacking %d packs", len(plan.repackPacks))
+			}
+			v, max := bar.Get()
+			return fmt.Sprintf("prune: repacking %d%%", v*100/max)
+		})
 		_, err := Repack(ctx, repo, repo, plan.repackPacks, plan.keepBlobs, bar)
 		bar.Done()
 		if err != nil {
@@ -587,6 +621,7 @@ func (plan *PrunePlan) Execute(ctx context.Context, printer progress.Printer) (e
 			return errors.Fatalf("%s", err)
 		}
 	} else if len(plan.ignorePacks) != 0 {
+		setNote("prune: rebuilding index")
 		err = rebuildIndexFiles(ctx, repo, plan.ignorePacks, nil, false, printer)
 		if err != nil {
 			return errors.Fatalf("%s", err)
@@ -595,6 +630,7 @@ func (plan *PrunePlan) Execute(ctx context.Context, printer progress.Printer) (e
 
 	if len(plan.removePacks) != 0 {
 		printer.P("removing %d old packs\n", len(plan.removePacks))
+		setNote("prune: removing old packs")
 		_ = deleteFiles(ctx, true, repo, plan.removePacks, restic.PackFile, printer)
 	}
 	if ctx.Err() != nil {
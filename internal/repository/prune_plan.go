@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/minio/sha256-simd"
+	"github.com/restic/restic/internal/errors"
+	"github.com/restic/restic/internal/restic"
+)
+
+// ErrPrunePlanStale is returned by LoadPrunePlan if the repository was
+// modified after the plan was created.
+var ErrPrunePlanStale = errors.Fatal("repository has changed since the prune plan was created")
+
+// prunePlanVersion is the version of the serialization format of a prune plan.
+const prunePlanVersion = 1
+
+// prunePlanFile is the serialized representation of a PrunePlan.
+type prunePlanFile struct {
+	Version      int       `json:"version"`
+	RepositoryID string    `json:"repository_id"`
+	Fingerprint  restic.ID `json:"fingerprint"`
+
+	RemovePacksFirst restic.IDs          `json:"remove_packs_first"`
+	RepackPacks      restic.IDs          `json:"repack_packs"`
+	KeepBlobs        []restic.BlobHandle `json:"keep_blobs"`
+	RemovePacks      restic.IDs          `json:"remove_packs"`
+	IgnorePacks      restic.IDs          `json:"ignore_packs"`
+
+	Stats PruneStats `json:"stats"`
+}
+
+// pruneFingerprint returns a hash over the IDs of all index and snapshot files
+// in the repository. It changes whenever data is added to or removed from the
+// repository, or snapshots are created or forgotten.
+func pruneFingerprint(ctx context.Context, repo restic.Repository) (restic.ID, error) {
+	h := sha256.New()
+	for _, tpe := range []restic.FileType{restic.IndexFile, restic.SnapshotFile} {
+		var ids restic.IDs
+		err := repo.List(ctx, tpe, func(id restic.ID, _ int64) error {
+			ids = append(ids, id)
+			return nil
+		})
+		if err != nil {
+			return restic.ID{}, err
+		}
+		sort.Sort(ids)
+
+		_, _ = h.Write([]byte(tpe.String()))
+		for _, id := range ids {
+			_, _ = h.Write(id[:])
+		}
+	}
+	return restic.IDFromHash(h.Sum(nil)), nil
+}
+
+func sortedIDs(ids restic.IDSet) restic.IDs {
+	list := ids.List()
+	sort.Sort(list)
+	return list
+}
+
+// Save writes the plan to w, such that it can be executed later on using
+// LoadPrunePlan. The plan contains a fingerprint of the current repository
+// state, which is used to detect whether the repository was modified in the
+// meantime.
+func (plan *PrunePlan) Save(ctx context.Context, w io.Writer) error {
+	if plan.opts.UnsafeRecovery {
+		return errors.New("prune plans cannot be saved for unsafe recovery")
+	}
+
+	fingerprint, err := pruneFingerprint(ctx, plan.repo)
+	if err != nil {
+		return err
+	}
+
+	keepBlobs := restic.BlobHandles{}
+	for bh := range plan.keepBlobs {
+		keepBlobs = append(keepBlobs, bh)
+	}
+	sort.Sort(keepBlobs)
+
+	f := prunePlanFile{
+		Version:      prunePlanVersion,
+		RepositoryID: plan.repo.Config().ID,
+		Fingerprint:  fingerprint,
+
+		RemovePacksFirst: sortedIDs(plan.removePacksFirst),
+		RepackPacks:      sortedIDs(plan.repackPacks),
+		KeepBlobs:        keepBlobs,
+		RemovePacks:      sortedIDs(plan.removePacks),
+		IgnorePacks:      sortedIDs(plan.ignorePacks),
+
+		Stats: plan.stats,
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return errors.Wrap(enc.Encode(f), "Encode")
+}
+
+// LoadPrunePlan reads a plan which was written by PrunePlan.Save. It returns
+// ErrPrunePlanStale if the repository has changed since the plan was created.
+// Only DryRun is used from opts, all other options are determined by the plan.
+// The index of repo must already be loaded.
+func LoadPrunePlan(ctx context.Context, repo restic.Repository, rd io.Reader, opts PruneOptions) (*PrunePlan, error) {
+	var f prunePlanFile
+	err := json.NewDecoder(rd).Decode(&f)
+	if err != nil {
+		return nil, errors.Wrap(err, "Decode")
+	}
+
+	if f.Version != prunePlanVersion {
+		return nil, errors.Fatalf("unsupported prune plan version %d", f.Version)
+	}
+	if f.RepositoryID != repo.Config().ID {
+		return nil, errors.Fatal("prune plan was created for a different repository")
+	}
+
+	fingerprint, err := pruneFingerprint(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	if fingerprint != f.Fingerprint {
+		return nil, ErrPrunePlanStale
+	}
+
+	plan := &PrunePlan{
+		removePacksFirst: restic.NewIDSet(f.RemovePacksFirst...),
+		repackPacks:      restic.NewIDSet(f.RepackPacks...),
+		removePacks:      restic.NewIDSet(f.RemovePacks...),
+		ignorePacks:      restic.NewIDSet(f.IgnorePacks...),
+
+		repo:  repo,
+		stats: f.Stats,
+		opts:  PruneOptions{DryRun: opts.DryRun},
+	}
+	if len(f.KeepBlobs) > 0 {
+		plan.keepBlobs = restic.NewCountedBlobSet(f.KeepBlobs...)
+	}
+
+	return plan, nil
+}
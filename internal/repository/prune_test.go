@@ -1,10 +1,13 @@
 package repository_test
 
 import (
+	"bytes"
 	"context"
 	"math"
 	"testing"
+	"time"
 
+	"github.com/restic/restic/internal/backend"
 	"github.com/restic/restic/internal/checker"
 	"github.com/restic/restic/internal/repository"
 	"github.com/restic/restic/internal/restic"
@@ -103,3 +106,119 @@ func TestPrune(t *testing.T) {
 		})
 	}
 }
+
+func preparePrunePlan(t *testing.T) (*repository.Repository, restic.BlobSet, *bytes.Buffer) {
+	repo := repository.TestRepository(t).(*repository.Repository)
+	createRandomBlobs(t, repo, 4, 0.5, true)
+	createRandomBlobs(t, repo, 5, 0.5, true)
+	keep, _ := selectBlobs(t, repo, 0.5)
+
+	opts := repository.PruneOptions{
+		MaxRepackBytes: math.MaxUint64,
+		MaxUnusedBytes: func(used uint64) (unused uint64) { return 0 },
+	}
+	plan, err := repository.PlanPrune(context.TODO(), opts, repo, func(ctx context.Context, repo restic.Repository) (usedBlobs restic.CountedBlobSet, err error) {
+		return restic.NewCountedBlobSet(keep.List()...), nil
+	}, &progress.NoopPrinter{})
+	rtest.OK(t, err)
+
+	buf := &bytes.Buffer{}
+	rtest.OK(t, plan.Save(context.TODO(), buf))
+	return repo, keep, buf
+}
+
+func TestPrunePlanRoundTrip(t *testing.T) {
+	repo, keep, buf := preparePrunePlan(t)
+	before := listBlobs(repo)
+
+	// saving a plan must not modify the repository
+	repo = repository.TestOpenBackend(t, repo.Backend()).(*repository.Repository)
+	rtest.OK(t, repo.LoadIndex(context.TODO(), nil))
+	rtest.Assert(t, listBlobs(repo).Equals(before), "saving the plan modified the repository")
+
+	plan, err := repository.LoadPrunePlan(context.TODO(), repo, buf, repository.PruneOptions{})
+	rtest.OK(t, err)
+	rtest.OK(t, plan.Execute(context.TODO(), &progress.NoopPrinter{}))
+
+	repo = repository.TestOpenBackend(t, repo.Backend()).(*repository.Repository)
+	checker.TestCheckRepo(t, repo, true)
+
+	existing := listBlobs(repo)
+	rtest.Assert(t, existing.Equals(keep), "unexpected blobs, wanted %v got %v", keep, existing)
+}
+
+func TestPrunePlanStale(t *testing.T) {
+	repo, _, buf := preparePrunePlan(t)
+
+	// add new data after the plan was created
+	createRandomBlobs(t, repo, 1, 0.5, true)
+
+	rtest.OK(t, repo.LoadIndex(context.TODO(), nil))
+	_, err := repository.LoadPrunePlan(context.TODO(), repo, buf, repository.PruneOptions{})
+	rtest.Assert(t, err == repository.ErrPrunePlanStale, "expected stale plan error, got %v", err)
+}
+
+// modTimeBackend reports the modification times from modTimes in List.
+type modTimeBackend struct {
+	backend.Backend
+	modTimes map[string]time.Time
+}
+
+func (be *modTimeBackend) List(ctx context.Context, t backend.FileType, fn func(backend.FileInfo) error) error {
+	return be.Backend.List(ctx, t, func(fi backend.FileInfo) error {
+		fi.ModTime = be.modTimes[fi.Name]
+		return fn(fi)
+	})
+}
+
+func TestPruneRepackOlderThan(t *testing.T) {
+	be := &modTimeBackend{Backend: repository.TestBackend(t), modTimes: make(map[string]time.Time)}
+	repo := repository.TestRepositoryWithBackend(t, be, 0, repository.Options{}).(*repository.Repository)
+	for i := 0; i < 6; i++ {
+		createRandomBlobs(t, repo, 3, 1, false)
+	}
+
+	// every third pack is old, packs without modification time are never old
+	oldPacks := restic.NewIDSet()
+	youngPacks := restic.NewIDSet()
+	i := 0
+	for id := range listPacks(t, repo) {
+		switch i % 3 {
+		case 0:
+			be.modTimes[id.String()] = time.Now().Add(-48 * time.Hour)
+			oldPacks.Insert(id)
+		case 1:
+			be.modTimes[id.String()] = time.Now().Add(-time.Hour)
+			youngPacks.Insert(id)
+		default:
+			youngPacks.Insert(id)
+		}
+		i++
+	}
+	rtest.Assert(t, len(oldPacks) > 0, "no old packs")
+	keep := listBlobs(repo)
+
+	opts := repository.PruneOptions{
+		MaxRepackBytes:  math.MaxUint64,
+		MaxUnusedBytes:  func(used uint64) (unused uint64) { return math.MaxUint64 },
+		RepackOlderThan: 24 * time.Hour,
+	}
+	plan, err := repository.PlanPrune(context.TODO(), opts, repo, func(ctx context.Context, repo restic.Repository) (usedBlobs restic.CountedBlobSet, err error) {
+		return restic.NewCountedBlobSet(keep.List()...), nil
+	}, &progress.NoopPrinter{})
+	rtest.OK(t, err)
+	rtest.Equals(t, uint(len(oldPacks)), plan.Stats().Packs.Repack)
+	rtest.OK(t, plan.Execute(context.TODO(), &progress.NoopPrinter{}))
+
+	packs := listPacks(t, repo)
+	for id := range oldPacks {
+		rtest.Assert(t, !packs.Has(id), "old pack %v was not repacked", id.Str())
+	}
+	for id := range youngPacks {
+		rtest.Assert(t, packs.Has(id), "young pack %v was repacked", id.Str())
+	}
+
+	repo = repository.TestOpenBackend(t, repo.Backend()).(*repository.Repository)
+	checker.TestCheckRepo(t, repo, true)
+	rtest.Assert(t, listBlobs(repo).Equals(keep), "unexpected blobs after repacking")
+}
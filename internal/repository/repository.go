@@ -10,6 +10,7 @@ import (
 	"runtime"
 	"sort"
 	"sync"
+	"sync/atomic"
 
 	"github.com/klauspost/compress/zstd"
 	"github.com/restic/chunker"
@@ -53,12 +54,22 @@ type Repository struct {
 	allocDec sync.Once
 	enc      *zstd.Encoder
 	dec      *zstd.Decoder
+
+	// estimatedSize is the approximate size of all pack files in the
+	// repository, it is only tracked if AutoPackSize is enabled.
+	estimatedSize atomic.Uint64
 }
 
 type Options struct {
-	Compression   CompressionMode
-	PackSize      uint
+	Compression CompressionMode
+	PackSize    uint
+	// AutoPackSize scales the target pack size with the repository size, see
+	// autoPackSize. PackSize is ignored if it is set.
+	AutoPackSize  bool
 	NoExtraVerify bool
+	// BlobTransformer is applied to the encrypted blobs stored in pack files.
+	// All operations on a repository must use the same transformer.
+	BlobTransformer restic.BlobTransformer
 }
 
 // CompressionMode configures if data should be compressed.
@@ -112,7 +123,7 @@ func New(be backend.Backend, opts Options) (*Repository, error) {
 		return nil, errors.New("invalid compression mode")
 	}
 
-	if opts.PackSize == 0 {
+	if opts.PackSize == 0 || opts.AutoPackSize {
 		opts.PackSize = DefaultPackSize
 	}
 	if opts.PackSize > MaxPackSize {
@@ -148,9 +159,46 @@ func (r *Repository) Config() restic.Config {
 
 // PackSize return the target size of a pack file when uploading
 func (r *Repository) PackSize() uint {
+	if r.opts.AutoPackSize {
+		return autoPackSize(r.estimatedSize.Load())
+	}
 	return r.opts.PackSize
 }
 
+// targetPackCount is the number of pack files up to which a repository uses
+// the minimum pack size if AutoPackSize is enabled.
+const targetPackCount = 8192
+
+// autoPackSize returns the target pack size for a repository which contains
+// repoSize bytes. The pack size is chosen such that the repository consists of
+// roughly targetPackCount pack files. It is rounded down to a power of two and
+// clamped to the range between MinPackSize and MaxPackSize. This results in
+// the following curve:
+//
+//	repository size    pack size
+//	  <=  32 GiB          4 MiB
+//	      64 GiB          8 MiB
+//	     128 GiB         16 MiB
+//	  >=   1 TiB        128 MiB
+//
+// Thus, small repositories profit from small packs which can be cached
+// efficiently, whereas large repositories are not split into too many files.
+func autoPackSize(repoSize uint64) uint {
+	size := repoSize / targetPackCount
+	if size <= MinPackSize {
+		return MinPackSize
+	}
+	if size >= MaxPackSize {
+		return MaxPackSize
+	}
+
+	packSize := uint64(MinPackSize)
+	for packSize*2 <= size {
+		packSize *= 2
+	}
+	return uint(packSize)
+}
+
 // UseCache replaces the backend with the wrapped cache.
 func (r *Repository) UseCache(c *cache.Cache) {
 	if c == nil {
@@ -302,8 +350,21 @@ func (r *Repository) LoadBlob(ctx context.Context, t restic.BlobType, id restic.
 			continue
 		}
 
+		data := buf
+		if r.opts.BlobTransformer != nil {
+			data, err = r.opts.BlobTransformer.Reverse(restic.BlobHandle{ID: id, Type: t}, buf)
+			if err != nil {
+				lastError = errors.Errorf("reversing transformation of blob %v failed: %v", id, err)
+				continue
+			}
+			if len(data) <= r.key.NonceSize() {
+				lastError = errors.Errorf("reversing transformation of blob %v failed: invalid length %d", id, len(data))
+				continue
+			}
+		}
+
 		// decrypt
-		nonce, ciphertext := buf[:r.key.NonceSize()], buf[r.key.NonceSize():]
+		nonce, ciphertext := data[:r.key.NonceSize()], data[r.key.NonceSize():]
 		plaintext, err := r.key.Open(ciphertext[:0], nonce, ciphertext, nil)
 		if err != nil {
 			lastError = errors.Errorf("decrypting blob %v failed: %v", id, err)
@@ -423,6 +484,13 @@ func (r *Repository) saveAndEncrypt(ctx context.Context, t restic.BlobType, data
 		return 0, fmt.Errorf("Detected data corruption while saving blob %v: %w\nCorrupted blobs are either caused by hardware issues or software bugs. Please open an issue at https://github.com/restic/restic/issues/new/choose for further troubleshooting.", id, err)
 	}
 
+	if r.opts.BlobTransformer != nil {
+		ciphertext, err = r.transformBlob(restic.BlobHandle{ID: id, Type: t}, ciphertext, uncompressedLength)
+		if err != nil {
+			return 0, err
+		}
+	}
+
 	// find suitable packer and add blob
 	var pm *packerManager
 
@@ -438,6 +506,30 @@ func (r *Repository) saveAndEncrypt(ctx context.Context, t restic.BlobType, data
 	return pm.SaveBlob(ctx, t, id, ciphertext, uncompressedLength)
 }
 
+// transformBlob applies the BlobTransformer to the ciphertext of a blob and
+// verifies that the transformation can be reversed.
+func (r *Repository) transformBlob(h restic.BlobHandle, ciphertext []byte, uncompressedLength int) ([]byte, error) {
+	data, err := r.opts.BlobTransformer.Transform(h, ciphertext)
+	if err != nil {
+		return nil, fmt.Errorf("transforming blob %v failed: %w", h, err)
+	}
+	if uncompressedLength == 0 && len(data) != len(ciphertext) {
+		// the plaintext length of uncompressed blobs is derived from the length in the pack
+		return nil, fmt.Errorf("transforming blob %v failed: length of uncompressed blob changed from %d to %d bytes", h, len(ciphertext), len(data))
+	}
+
+	if !r.opts.NoExtraVerify {
+		reversed, err := r.opts.BlobTransformer.Reverse(h, data)
+		if err != nil {
+			return nil, fmt.Errorf("reversing transformation of blob %v failed: %w", h, err)
+		}
+		if !bytes.Equal(reversed, ciphertext) {
+			return nil, fmt.Errorf("transformation of blob %v is not reversible", h)
+		}
+	}
+	return data, nil
+}
+
 func (r *Repository) verifyCiphertext(buf []byte, uncompressedLength int, id restic.ID) error {
 	if r.opts.NoExtraVerify {
 		return nil
@@ -583,6 +675,10 @@ func (r *Repository) StartPackUploader(ctx context.Context, wg *errgroup.Group)
 	r.uploader = newPackerUploader(ctx, innerWg, r, r.be.Connections())
 	r.treePM = newPackerManager(r.key, restic.TreeBlob, r.PackSize(), r.uploader.QueuePacker)
 	r.dataPM = newPackerManager(r.key, restic.DataBlob, r.PackSize(), r.uploader.QueuePacker)
+	if r.opts.AutoPackSize {
+		r.treePM.packSizeFn = r.PackSize
+		r.dataPM.packSizeFn = r.PackSize
+	}
 
 	wg.Go(func() error {
 		return innerWg.Wait()
@@ -694,6 +790,18 @@ func (r *Repository) LoadIndex(ctx context.Context, p *progress.Counter) error {
 	// Trigger GC to reset garbage collection threshold
 	runtime.GC()
 
+	if r.opts.AutoPackSize {
+		var size uint64
+		err := r.idx.Each(ctx, func(blob restic.PackedBlob) {
+			size += uint64(blob.Length)
+		})
+		if err != nil {
+			return err
+		}
+		r.estimatedSize.Store(size)
+		debug.Log("estimated repository size %d, using pack size %d", size, r.PackSize())
+	}
+
 	if r.cfg.Version < 2 {
 		// sanity check
 		ctx, cancel := context.WithCancel(ctx)
@@ -887,6 +995,11 @@ func (r *Repository) Key() *crypto.Key {
 	return r.key
 }
 
+// BlobTransformer returns the transformer for blobs in pack files, or nil.
+func (r *Repository) BlobTransformer() restic.BlobTransformer {
+	return r.opts.BlobTransformer
+}
+
 // KeyID returns the id of the current key in the backend.
 func (r *Repository) KeyID() restic.ID {
 	return r.keyID
@@ -973,10 +1086,10 @@ const maxUnusedRange = 1 * 1024 * 1024
 // then LoadBlobsFromPack will abort and not retry it. The buf passed to the callback is only valid within
 // this specific call. The callback must not keep a reference to buf.
 func (r *Repository) LoadBlobsFromPack(ctx context.Context, packID restic.ID, blobs []restic.Blob, handleBlobFn func(blob restic.BlobHandle, buf []byte, err error) error) error {
-	return streamPack(ctx, r.Backend().Load, r.LoadBlob, r.getZstdDecoder(), r.key, packID, blobs, handleBlobFn)
+	return streamPack(ctx, r.Backend().Load, r.LoadBlob, r.getZstdDecoder(), r.key, r.opts.BlobTransformer, packID, blobs, handleBlobFn)
 }
 
-func streamPack(ctx context.Context, beLoad backendLoadFn, loadBlobFn loadBlobFn, dec *zstd.Decoder, key *crypto.Key, packID restic.ID, blobs []restic.Blob, handleBlobFn func(blob restic.BlobHandle, buf []byte, err error) error) error {
+func streamPack(ctx context.Context, beLoad backendLoadFn, loadBlobFn loadBlobFn, dec *zstd.Decoder, key *crypto.Key, tf restic.BlobTransformer, packID restic.ID, blobs []restic.Blob, handleBlobFn func(blob restic.BlobHandle, buf []byte, err error) error) error {
 	if len(blobs) == 0 {
 		// nothing to do
 		return nil
@@ -1010,7 +1123,7 @@ func streamPack(ctx context.Context, beLoad backendLoadFn, loadBlobFn loadBlobFn
 
 		if split {
 			// load everything up to the skipped file section
-			err := streamPackPart(ctx, beLoad, loadBlobFn, dec, key, packID, blobs[lowerIdx:i], handleBlobFn)
+			err := streamPackPart(ctx, beLoad, loadBlobFn, dec, key, tf, packID, blobs[lowerIdx:i], handleBlobFn)
 			if err != nil {
 				return err
 			}
@@ -1019,10 +1132,10 @@ func streamPack(ctx context.Context, beLoad backendLoadFn, loadBlobFn loadBlobFn
 		lastPos = blobs[i].Offset + blobs[i].Length
 	}
 	// load remainder
-	return streamPackPart(ctx, beLoad, loadBlobFn, dec, key, packID, blobs[lowerIdx:], handleBlobFn)
+	return streamPackPart(ctx, beLoad, loadBlobFn, dec, key, tf, packID, blobs[lowerIdx:], handleBlobFn)
 }
 
-func streamPackPart(ctx context.Context, beLoad backendLoadFn, loadBlobFn loadBlobFn, dec *zstd.Decoder, key *crypto.Key, packID restic.ID, blobs []restic.Blob, handleBlobFn func(blob restic.BlobHandle, buf []byte, err error) error) error {
+func streamPackPart(ctx context.Context, beLoad backendLoadFn, loadBlobFn loadBlobFn, dec *zstd.Decoder, key *crypto.Key, tf restic.BlobTransformer, packID restic.ID, blobs []restic.Blob, handleBlobFn func(blob restic.BlobHandle, buf []byte, err error) error) error {
 	h := backend.Handle{Type: restic.PackFile, Name: packID.String(), IsMetadata: false}
 
 	dataStart := blobs[0].Offset
@@ -1054,7 +1167,7 @@ func streamPackPart(ctx context.Context, beLoad backendLoadFn, loadBlobFn loadBl
 		return errors.Wrap(err, "StreamPack")
 	}
 
-	it := NewPackBlobIterator(packID, newByteReader(data), dataStart, blobs, key, dec)
+	it := NewPackBlobIterator(packID, newByteReader(data), dataStart, blobs, key, tf, dec)
 
 	for {
 		val, err := it.Next()
@@ -1130,6 +1243,7 @@ type PackBlobIterator struct {
 
 	blobs []restic.Blob
 	key   *crypto.Key
+	tf    restic.BlobTransformer
 	dec   *zstd.Decoder
 
 	decode []byte
@@ -1144,13 +1258,14 @@ type PackBlobValue struct {
 var ErrPackEOF = errors.New("reached EOF of pack file")
 
 func NewPackBlobIterator(packID restic.ID, rd discardReader, currentOffset uint,
-	blobs []restic.Blob, key *crypto.Key, dec *zstd.Decoder) *PackBlobIterator {
+	blobs []restic.Blob, key *crypto.Key, tf restic.BlobTransformer, dec *zstd.Decoder) *PackBlobIterator {
 	return &PackBlobIterator{
 		packID:        packID,
 		rd:            rd,
 		currentOffset: currentOffset,
 		blobs:         blobs,
 		key:           key,
+		tf:            tf,
 		dec:           dec,
 	}
 }
@@ -1186,7 +1301,15 @@ func (b *PackBlobIterator) Next() (PackBlobValue, error) {
 
 	b.currentOffset = entry.Offset + entry.Length
 
-	if int(entry.Length) <= b.key.NonceSize() {
+	if b.tf != nil {
+		buf, err = b.tf.Reverse(h, buf)
+		if err != nil {
+			return PackBlobValue{entry.BlobHandle, nil, fmt.Errorf("reversing transformation of blob %v from %v failed: %w", h, b.packID.Str(), err)}, nil
+		}
+		if len(buf) <= b.key.NonceSize() {
+			return PackBlobValue{entry.BlobHandle, nil, fmt.Errorf("reversing transformation of blob %v from %v failed: invalid length %d", h, b.packID.Str(), len(buf))}, nil
+		}
+	} else if int(entry.Length) <= b.key.NonceSize() {
 		debug.Log("%v", b.blobs)
 		return PackBlobValue{}, fmt.Errorf("invalid blob length %v", entry)
 	}
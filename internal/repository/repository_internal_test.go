@@ -276,7 +276,7 @@ func testStreamPack(t *testing.T, version uint) {
 
 				loadCalls = 0
 				shortFirstLoad = test.shortFirstLoad
-				err := streamPack(ctx, load, nil, dec, &key, restic.ID{}, test.blobs, handleBlob)
+				err := streamPack(ctx, load, nil, dec, &key, nil, restic.ID{}, test.blobs, handleBlob)
 				if err != nil {
 					t.Fatal(err)
 				}
@@ -339,7 +339,7 @@ func testStreamPack(t *testing.T, version uint) {
 					return err
 				}
 
-				err := streamPack(ctx, load, nil, dec, &key, restic.ID{}, test.blobs, handleBlob)
+				err := streamPack(ctx, load, nil, dec, &key, nil, restic.ID{}, test.blobs, handleBlob)
 				if err == nil {
 					t.Fatalf("wanted error %v, got nil", test.err)
 				}
@@ -515,7 +515,7 @@ func TestStreamPackFallback(t *testing.T) {
 			return err
 		}
 
-		err := streamPack(ctx, loadPack, loadBlob, dec, &key, restic.ID{}, blobs, handleBlob)
+		err := streamPack(ctx, loadPack, loadBlob, dec, &key, nil, restic.ID{}, blobs, handleBlob)
 		rtest.OK(t, err)
 		rtest.Assert(t, blobOK, "blob failed to load")
 	}
@@ -529,3 +529,32 @@ func TestStreamPackFallback(t *testing.T) {
 		test(t, true)
 	})
 }
+
+func TestAutoPackSize(t *testing.T) {
+	const GiB = 1024 * 1024 * 1024
+	for _, test := range []struct {
+		repoSize uint64
+		packSize uint
+	}{
+		{0, MinPackSize},
+		{1 * GiB, MinPackSize},
+		{32 * GiB, MinPackSize},
+		{63 * GiB, MinPackSize},
+		{64 * GiB, 8 * 1024 * 1024},
+		{128 * GiB, 16 * 1024 * 1024},
+		{300 * GiB, 32 * 1024 * 1024},
+		{1024 * GiB, MaxPackSize},
+		{100 * 1024 * GiB, MaxPackSize},
+	} {
+		packSize := autoPackSize(test.repoSize)
+		rtest.Assert(t, packSize == test.packSize, "repository size %v: want pack size %v, got %v", test.repoSize, test.packSize, packSize)
+	}
+
+	// the pack size must never shrink while the repository grows
+	last := uint(0)
+	for size := uint64(0); size <= 2048*GiB; size += GiB {
+		packSize := autoPackSize(size)
+		rtest.Assert(t, packSize >= last, "pack size shrinks from %v to %v at %v", last, packSize, size)
+		last = packSize
+	}
+}
@@ -2,6 +2,7 @@ package restic
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/signal"
@@ -35,6 +36,8 @@ type Lock struct {
 	PID       int       `json:"pid"`
 	UID       uint32    `json:"uid,omitempty"`
 	GID       uint32    `json:"gid,omitempty"`
+	// Note optionally describes the progress of the operation holding the lock.
+	Note string `json:"note,omitempty"`
 
 	repo   Repository
 	lockID *ID
@@ -212,7 +215,16 @@ func (l *Lock) checkForOtherLocks(ctx context.Context) error {
 
 // createLock acquires the lock by creating a file in the repository.
 func (l *Lock) createLock(ctx context.Context) (ID, error) {
-	id, err := SaveJSONUnpacked(ctx, l.repo, LockFile, l)
+	// serialize the lock while holding the mutex, as the note and the
+	// timestamp can be modified concurrently
+	l.lock.Lock()
+	buf, err := json.Marshal(l)
+	l.lock.Unlock()
+	if err != nil {
+		return ID{}, errors.Wrap(err, "json.Marshal")
+	}
+
+	id, err := l.repo.SaveUnpacked(ctx, LockFile, buf)
 	if err != nil {
 		return ID{}, err
 	}
@@ -220,6 +232,14 @@ func (l *Lock) createLock(ctx context.Context) (ID, error) {
 	return id, nil
 }
 
+// SetNote sets the note of the lock. The note is stored in the repository
+// the next time the lock is refreshed.
+func (l *Lock) SetNote(note string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+	l.Note = note
+}
+
 // Unlock removes the lock from the repository.
 func (l *Lock) Unlock() error {
 	if l == nil || l.lockID == nil {
@@ -267,6 +287,38 @@ func (l *Lock) Stale() bool {
 	return false
 }
 
+// provenStale returns true only if the lock is known to be stale. This is the
+// case if it was created on the current machine and the process does not
+// exist any more, or if it was created on another machine and has not been
+// refreshed for maxAge. Unlike Stale, a lock of a process which is still
+// running is never considered stale. If the state of a lock created on
+// another machine cannot be determined, a description why it may still be in
+// use is returned.
+func (l *Lock) provenStale(maxAge time.Duration) (stale bool, ambiguous string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	hn, err := os.Hostname()
+	if err != nil {
+		debug.Log("unable to find current hostname: %v", err)
+		return false, fmt.Sprintf("unable to determine the current hostname: %v", err)
+	}
+
+	if hn == l.Hostname {
+		if l.processExists() {
+			debug.Log("process %d of lock %v is still running", l.PID, l.lockID)
+			return false, ""
+		}
+		return true, ""
+	}
+
+	age := time.Since(l.Time)
+	if age > maxAge {
+		return true, ""
+	}
+	return false, fmt.Sprintf("created on host %v and refreshed %v ago", l.Hostname, age.Round(time.Second))
+}
+
 // Refresh refreshes the lock by creating a new file in the backend with a new
 // timestamp. Afterwards the old lock is removed.
 func (l *Lock) Refresh(ctx context.Context) error {
@@ -359,6 +411,9 @@ func (l *Lock) String() string {
 		l.PID, l.Hostname, l.Username, l.UID, l.GID,
 		l.Time.Format("2006-01-02 15:04:05"), time.Since(l.Time),
 		l.lockID.Str())
+	if l.Note != "" {
+		text += fmt.Sprintf("\nnote: %s", l.Note)
+	}
 
 	return text
 }
@@ -412,6 +467,38 @@ func RemoveStaleLocks(ctx context.Context, repo Repository) (uint, error) {
 	return processed, err
 }
 
+// RemoveProvenStaleLocks deletes only the locks which are known to be stale:
+// locks created on the current machine by processes which do not exist any
+// more and locks created on other machines which were not refreshed for
+// maxAge. Locks which may still be in use by another machine or which cannot
+// be loaded are never removed, instead they are passed to report together with
+// the reason. The lock is nil if it could not be loaded.
+func RemoveProvenStaleLocks(ctx context.Context, repo Repository, maxAge time.Duration, report func(id ID, lock *Lock, reason string)) (uint, error) {
+	var processed uint
+	err := ForAllLocks(ctx, repo, nil, func(id ID, lock *Lock, err error) error {
+		if err != nil {
+			debug.Log("ignore lock %v: %v", id, err)
+			report(id, nil, fmt.Sprintf("unable to load lock: %v", err))
+			return nil
+		}
+
+		stale, ambiguous := lock.provenStale(maxAge)
+		if ambiguous != "" {
+			report(id, lock, ambiguous)
+		}
+		if !stale {
+			return nil
+		}
+
+		err = repo.Backend().Remove(ctx, backend.Handle{Type: LockFile, Name: id.String()})
+		if err == nil {
+			processed++
+		}
+		return err
+	})
+	return processed, err
+}
+
 // RemoveAllLocks removes all locks forcefully.
 func RemoveAllLocks(ctx context.Context, repo Repository) (uint, error) {
 	var processed uint32
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -228,6 +229,56 @@ func TestLockWithStaleLock(t *testing.T) {
 	rtest.OK(t, removeLock(repo, id2))
 }
 
+func TestRemoveProvenStaleLocks(t *testing.T) {
+	repo := repository.TestRepository(t)
+
+	// the process is still running, thus the lock is kept despite its age
+	live, err := createFakeLock(repo, time.Now().Add(-time.Hour), os.Getpid())
+	rtest.OK(t, err)
+
+	staleLocal, err := createFakeLock(repo, time.Now().Add(-time.Minute), os.Getpid()+500000)
+	rtest.OK(t, err)
+
+	hostname, err := os.Hostname()
+	rtest.OK(t, err)
+	oldRemote, err := restic.SaveJSONUnpacked(context.TODO(), repo, restic.LockFile,
+		&restic.Lock{Time: time.Now().Add(-2 * time.Hour), PID: os.Getpid(), Hostname: "other-" + hostname})
+	rtest.OK(t, err)
+	recentRemote, err := restic.SaveJSONUnpacked(context.TODO(), repo, restic.LockFile,
+		&restic.Lock{Time: time.Now().Add(-10 * time.Minute), PID: os.Getpid() + 500000, Hostname: "other-" + hostname})
+	rtest.OK(t, err)
+
+	invalid, err := repo.SaveUnpacked(context.TODO(), restic.LockFile, []byte("invalid lock"))
+	rtest.OK(t, err)
+
+	reported := make(map[restic.ID]string)
+	processed, err := restic.RemoveProvenStaleLocks(context.TODO(), repo, time.Hour, func(id restic.ID, _ *restic.Lock, reason string) {
+		reported[id] = reason
+	})
+	rtest.OK(t, err)
+	rtest.Equals(t, uint(2), processed)
+
+	for _, test := range []struct {
+		name     string
+		id       restic.ID
+		exists   bool
+		reported bool
+	}{
+		{"live", live, true, false},
+		{"stale-local", staleLocal, false, false},
+		{"old-remote", oldRemote, false, false},
+		{"recent-remote", recentRemote, true, true},
+		{"invalid", invalid, true, true},
+	} {
+		rtest.Assert(t, lockExists(repo, t, test.id) == test.exists,
+			"lock %v: expected exists %v", test.name, test.exists)
+		_, ok := reported[test.id]
+		rtest.Assert(t, ok == test.reported, "lock %v: expected reported %v, got %q", test.name, test.reported, reported[test.id])
+	}
+	rtest.Assert(t, strings.Contains(reported[recentRemote], "other-"+hostname),
+		"reason does not mention the host: %q", reported[recentRemote])
+}
+
 func TestRemoveAllLocks(t *testing.T) {
 	repo := repository.TestRepository(t)
 
@@ -309,6 +360,25 @@ func TestLockRefreshStale(t *testing.T) {
 	})
 }
 
+func TestLockNote(t *testing.T) {
+	repo := repository.TestRepository(t)
+	restic.TestSetLockTimeout(t, 5*time.Millisecond)
+
+	lock, err := restic.NewLock(context.TODO(), repo)
+	rtest.OK(t, err)
+
+	for _, note := range []string{"prune: repacking 10%", "prune: repacking 40%", ""} {
+		lock.SetNote(note)
+		rtest.OK(t, lock.Refresh(context.TODO()))
+
+		lock2, err := restic.LoadLock(context.TODO(), repo, checkSingleLock(t, repo))
+		rtest.OK(t, err)
+		rtest.Equals(t, note, lock2.Note)
+		rtest.Equals(t, note != "", strings.Contains(lock2.String(), "note: "+note))
+	}
+	rtest.OK(t, lock.Unlock())
+}
+
 func TestLockRefreshStaleMissing(t *testing.T) {
 	repo := repository.TestRepository(t)
 	restic.TestSetLockTimeout(t, 5*time.Millisecond)
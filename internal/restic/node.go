@@ -96,6 +96,9 @@ type Node struct {
 	Device             uint64                                   `json:"device,omitempty"` // in case of Type == "dev", stat.st_rdev
 	Content            IDs                                      `json:"content"`
 	Subtree            *ID                                      `json:"subtree,omitempty"`
+	// ContentHash is the SHA-256 hash of the whole file content. It is only
+	// set for files if requested during backup.
+	ContentHash *ID `json:"content_hash,omitempty"`
 
 	Error string `json:"error,omitempty"`
 
@@ -508,6 +511,19 @@ func (node Node) Equals(other Node) bool {
 			return false
 		}
 	}
+	if node.ContentHash != nil {
+		if other.ContentHash == nil {
+			return false
+		}
+
+		if !node.ContentHash.Equal(*other.ContentHash) {
+			return false
+		}
+	} else {
+		if other.ContentHash != nil {
+			return false
+		}
+	}
 	if node.Error != other.Error {
 		return false
 	}
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"runtime"
+	"strings"
 	"testing"
 	"time"
 
@@ -375,3 +376,26 @@ func TestSymlinkSerializationFormat(t *testing.T) {
 		test.Assert(t, n2.LinkTargetRaw == nil, "quoted link target is just a helper field and must be unset after decoding")
 	}
 }
+
+func TestContentHashSerialization(t *testing.T) {
+	id := Hash([]byte("foo"))
+	n := Node{
+		Name:        "foo",
+		Type:        "file",
+		Content:     IDs{id},
+		ContentHash: &id,
+	}
+	ser, err := json.Marshal(n)
+	test.OK(t, err)
+
+	var n2 Node
+	test.OK(t, json.Unmarshal(ser, &n2))
+	test.Assert(t, n.Equals(n2), "nodes differ after round trip: %v vs. %v", n, n2)
+
+	n2.ContentHash = nil
+	test.Assert(t, !n.Equals(n2), "nodes with and without content hash are equal")
+
+	ser, err = json.Marshal(n2)
+	test.OK(t, err)
+	test.Assert(t, !strings.Contains(string(ser), "content_hash"), "unset content hash is serialized: %s", ser)
+}
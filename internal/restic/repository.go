@@ -23,6 +23,9 @@ type Repository interface {
 	Connections() uint
 
 	Key() *crypto.Key
+	// BlobTransformer returns the transformer applied to blobs in pack files,
+	// or nil if there is none.
+	BlobTransformer() BlobTransformer
 
 	Index() MasterIndex
 	LoadIndex(context.Context, *progress.Counter) error
@@ -59,6 +62,21 @@ type Repository interface {
 	SaveUnpacked(context.Context, FileType, []byte) (ID, error)
 }
 
+// BlobTransformer modifies the encrypted representation of blobs at the
+// storage boundary. Transform is applied to the ciphertext of a blob (including
+// the nonce) right before it is added to a pack file. Reverse is applied after
+// the data was read from a pack file and before it is decrypted, it must
+// exactly undo Transform. As the blob ID is computed from the plaintext, it is
+// not affected by the transformation.
+//
+// The length of the data may only change for compressed blobs, the index
+// stores the length of the transformed data. Both functions must not modify or
+// retain the passed buffer and must be safe for concurrent use.
+type BlobTransformer interface {
+	Transform(h BlobHandle, ciphertext []byte) ([]byte, error)
+	Reverse(h BlobHandle, data []byte) ([]byte, error)
+}
+
 type FileType = backend.FileType
 
 // These are the different data types a backend can store.
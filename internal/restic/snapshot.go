@@ -5,10 +5,12 @@ import (
 	"fmt"
 	"os/user"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/errors"
 )
 
 // Snapshot is the state of a resource at one point in time.
@@ -25,6 +27,9 @@ type Snapshot struct {
 	Tags     []string  `json:"tags,omitempty"`
 	Original *ID       `json:"original,omitempty"`
 
+	// Annotations are arbitrary key/value pairs attached to the snapshot.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
 	ProgramVersion string           `json:"program_version,omitempty"`
 	Summary        *SnapshotSummary `json:"summary,omitempty"`
 
@@ -216,6 +221,56 @@ func (sn *Snapshot) HasTagList(l []TagList) bool {
 	return false
 }
 
+// Limits for the annotations of a snapshot.
+const (
+	MaxAnnotationKeyLength   = 128
+	MaxAnnotationValueLength = 1024
+)
+
+// ParseAnnotations parses a list of "key=value" pairs into a map. Keys must not
+// be empty and must not exceed MaxAnnotationKeyLength bytes, values must not
+// exceed MaxAnnotationValueLength bytes. If a key occurs multiple times, the
+// last value is used.
+func ParseAnnotations(list []string) (map[string]string, error) {
+	if len(list) == 0 {
+		return nil, nil
+	}
+
+	annotations := make(map[string]string, len(list))
+	for _, a := range list {
+		key, value, ok := strings.Cut(a, "=")
+		if !ok {
+			return nil, errors.Errorf("invalid annotation %q, must be of the form key=value", a)
+		}
+		if key == "" {
+			return nil, errors.Errorf("invalid annotation %q, key is empty", a)
+		}
+		if len(key) > MaxAnnotationKeyLength {
+			return nil, errors.Errorf("annotation key %q is longer than %d bytes", key, MaxAnnotationKeyLength)
+		}
+		if len(value) > MaxAnnotationValueLength {
+			return nil, errors.Errorf("value of annotation %q is longer than %d bytes", key, MaxAnnotationValueLength)
+		}
+		annotations[key] = value
+	}
+	return annotations, nil
+}
+
+// HasAnnotations returns true if the snapshot has all annotations in l. Each
+// entry is either of the form "key=value", which requires the annotation to
+// have that value, or "key", which only requires the annotation to exist.
+func (sn *Snapshot) HasAnnotations(l []string) bool {
+	for _, a := range l {
+		key, value, hasValue := strings.Cut(a, "=")
+		v, ok := sn.Annotations[key]
+		if !ok || (hasValue && v != value) {
+			return false
+		}
+	}
+
+	return true
+}
+
 // HasPaths returns true if the snapshot has all of the paths.
 func (sn *Snapshot) HasPaths(paths []string) bool {
 	m := make(map[string]struct{}, len(sn.Paths))
@@ -89,3 +89,43 @@ func TestFindAllSubpathError(t *testing.T) {
 		}))
 	test.Assert(t, count == 2, "unexpected number of subfolder errors: %v, wanted %v", count, 2)
 }
+
+func TestFindAllAnnotations(t *testing.T) {
+	repo := repository.TestRepository(t)
+
+	ids := make(map[string]restic.ID)
+	for name, annotations := range map[string]map[string]string{
+		"prod":    {"env": "prod", "ticket": "OPS-1"},
+		"staging": {"env": "staging"},
+		"none":    nil,
+	} {
+		sn := &restic.Snapshot{Time: parseTimeUTC("2019-09-09 09:09:09"), Annotations: annotations}
+		id, err := restic.SaveSnapshot(context.TODO(), repo, sn)
+		test.OK(t, err)
+		ids[name] = id
+	}
+
+	for _, tc := range []struct {
+		filter   []string
+		expected []string
+	}{
+		{[]string{"env=prod"}, []string{"prod"}},
+		{[]string{"env"}, []string{"prod", "staging"}},
+		{[]string{"env=staging", "ticket"}, nil},
+		{nil, []string{"prod", "staging", "none"}},
+	} {
+		found := restic.NewIDSet()
+		f := restic.SnapshotFilter{Annotations: tc.filter}
+		test.OK(t, f.FindAll(context.TODO(), repo, repo, nil, func(_ string, sn *restic.Snapshot, err error) error {
+			test.OK(t, err)
+			found.Insert(*sn.ID())
+			return nil
+		}))
+
+		expected := restic.NewIDSet()
+		for _, name := range tc.expected {
+			expected.Insert(ids[name])
+		}
+		test.Assert(t, found.Equals(expected), "filter %v: expected %v, got %v", tc.filter, expected, found)
+	}
+}
@@ -2,6 +2,8 @@ package restic_test
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 
@@ -49,3 +51,61 @@ func testLoadJSONUnpacked(t *testing.T, version uint) {
 	rtest.Equals(t, sn.Hostname, sn2.Hostname)
 	rtest.Equals(t, sn.Username, sn2.Username)
 }
+
+func TestParseAnnotations(t *testing.T) {
+	annotations, err := restic.ParseAnnotations([]string{"ticket=OPS-42", "env=prod", "empty=", "env=staging"})
+	rtest.OK(t, err)
+	rtest.Equals(t, map[string]string{"ticket": "OPS-42", "env": "staging", "empty": ""}, annotations)
+
+	annotations, err = restic.ParseAnnotations(nil)
+	rtest.OK(t, err)
+	rtest.Assert(t, annotations == nil, "expected nil annotations, got %v", annotations)
+
+	for _, invalid := range []string{
+		"novalue",
+		"=value",
+		strings.Repeat("k", restic.MaxAnnotationKeyLength+1) + "=value",
+		"key=" + strings.Repeat("v", restic.MaxAnnotationValueLength+1),
+	} {
+		_, err := restic.ParseAnnotations([]string{invalid})
+		rtest.Assert(t, err != nil, "expected error for annotation %q", invalid)
+	}
+}
+
+func TestHasAnnotations(t *testing.T) {
+	sn := &restic.Snapshot{Annotations: map[string]string{"ticket": "OPS-42", "env": "prod"}}
+
+	for _, test := range []struct {
+		filter []string
+		match  bool
+	}{
+		{nil, true},
+		{[]string{"env=prod"}, true},
+		{[]string{"env"}, true},
+		{[]string{"env=prod", "ticket=OPS-42"}, true},
+		{[]string{"env=staging"}, false},
+		{[]string{"env=prod", "owner"}, false},
+		{[]string{"env="}, false},
+	} {
+		rtest.Assert(t, sn.HasAnnotations(test.filter) == test.match, "filter %v: expected %v", test.filter, test.match)
+	}
+
+	rtest.Assert(t, !(&restic.Snapshot{}).HasAnnotations([]string{"env"}), "snapshot without annotations matched")
+}
+
+func TestAnnotationsRoundTrip(t *testing.T) {
+	repo := repository.TestRepository(t)
+
+	sn := restic.Snapshot{Annotations: map[string]string{"ticket": "OPS-42", "env": "prod"}}
+	id, err := restic.SaveSnapshot(context.TODO(), repo, &sn)
+	rtest.OK(t, err)
+
+	sn2, err := restic.LoadSnapshot(context.TODO(), repo, id)
+	rtest.OK(t, err)
+	rtest.Equals(t, sn.Annotations, sn2.Annotations)
+
+	// snapshots without annotations do not contain the field
+	buf, err := json.Marshal(&restic.Snapshot{})
+	rtest.OK(t, err)
+	rtest.Assert(t, !strings.Contains(string(buf), "annotations"), "unexpected annotations in %s", buf)
+}
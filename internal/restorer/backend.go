@@ -0,0 +1,106 @@
+package restorer
+
+import (
+	"context"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/debug"
+	"github.com/restic/restic/internal/errors"
+	"github.com/restic/restic/internal/fs"
+	"github.com/restic/restic/internal/restic"
+	"github.com/restic/restic/internal/walker"
+)
+
+// RestoreToBackend writes the content of all regular files in the snapshot sn
+// to the backend dst instead of a local directory. The name of each file in
+// dst is derived from keyTemplate, in which "{snapshot}" is replaced by the
+// short ID of the snapshot and "{path}" by the path of the file within the
+// snapshot, without a leading slash. The files are saved as restic.PackFile,
+// so the final location depends on the layout used by dst. Directories,
+// symlinks and other special files are skipped as dst cannot represent them.
+func RestoreToBackend(ctx context.Context, repo restic.Repository, sn *restic.Snapshot, dst backend.Backend, keyTemplate string) error {
+	if sn.Tree == nil {
+		return errors.Errorf("snapshot %v has no tree", sn.ID().Str())
+	}
+	if !strings.Contains(keyTemplate, "{path}") {
+		return errors.Errorf("key template %q does not contain {path}", keyTemplate)
+	}
+
+	snID := ""
+	if sn.ID() != nil {
+		snID = sn.ID().Str()
+	}
+
+	var buf []byte
+	return walker.Walk(ctx, repo, *sn.Tree, walker.WalkVisitor{
+		ProcessNode: func(_ restic.ID, nodepath string, node *restic.Node, nodeErr error) error {
+			if nodeErr != nil {
+				return nodeErr
+			}
+			if node == nil || node.Type != "file" {
+				return nil
+			}
+
+			key := strings.NewReplacer(
+				"{snapshot}", snID,
+				"{path}", strings.TrimPrefix(nodepath, "/"),
+			).Replace(keyTemplate)
+
+			var err error
+			buf, err = restoreFileToBackend(ctx, repo, node, dst, backend.Handle{Type: restic.PackFile, Name: key}, buf)
+			if err != nil {
+				return errors.Wrapf(err, "restore %v", nodepath)
+			}
+			return nil
+		},
+	})
+}
+
+// restoreFileToBackend assembles the content of node in a temporary file and
+// then saves it as h in dst.
+func restoreFileToBackend(ctx context.Context, repo restic.Repository, node *restic.Node, dst backend.Backend, h backend.Handle, buf []byte) ([]byte, error) {
+	debug.Log("restore %v to %v", node.Name, h)
+
+	tmpfile, err := fs.TempFile("", "restic-restore-")
+	if err != nil {
+		return buf, errors.WithStack(err)
+	}
+	defer func() {
+		_ = tmpfile.Close()
+		_ = fs.RemoveIfExists(tmpfile.Name())
+	}()
+
+	var w io.Writer = tmpfile
+	var hasher hash.Hash
+	if hasher = dst.Hasher(); hasher != nil {
+		w = io.MultiWriter(tmpfile, hasher)
+	}
+
+	for _, id := range node.Content {
+		buf, err = repo.LoadBlob(ctx, restic.DataBlob, id, buf)
+		if err != nil {
+			return buf, err
+		}
+		if _, err = w.Write(buf); err != nil {
+			return buf, errors.WithStack(err)
+		}
+	}
+
+	if _, err = tmpfile.Seek(0, io.SeekStart); err != nil {
+		return buf, errors.WithStack(err)
+	}
+
+	var sum []byte
+	if hasher != nil {
+		sum = hasher.Sum(nil)
+	}
+	rd, err := backend.NewFileReader(tmpfile, sum)
+	if err != nil {
+		return buf, err
+	}
+
+	return buf, dst.Save(ctx, h, rd)
+}
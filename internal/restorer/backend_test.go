@@ -0,0 +1,67 @@
+package restorer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/restic/restic/internal/backend"
+	"github.com/restic/restic/internal/backend/mem"
+	"github.com/restic/restic/internal/repository"
+	"github.com/restic/restic/internal/restic"
+	rtest "github.com/restic/restic/internal/test"
+)
+
+func TestRestoreToBackend(t *testing.T) {
+	repo := repository.TestRepository(t)
+	sn, _ := saveSnapshot(t, repo, Snapshot{
+		Nodes: map[string]Node{
+			"foo":   File{Data: "content: foo\n"},
+			"empty": File{Data: ""},
+			"dir": Dir{
+				Nodes: map[string]Node{
+					"bar": File{Data: "content: bar\n"},
+					"sub": Dir{
+						Nodes: map[string]Node{
+							"baz": File{Data: "content: baz\n"},
+						},
+					},
+				},
+			},
+		},
+	}, noopGetGenericAttributes)
+
+	dst := mem.New()
+	rtest.OK(t, RestoreToBackend(context.TODO(), repo, sn, dst, "restore/{path}"))
+
+	want := map[string]string{
+		"restore/foo":         "content: foo\n",
+		"restore/empty":       "",
+		"restore/dir/bar":     "content: bar\n",
+		"restore/dir/sub/baz": "content: baz\n",
+	}
+
+	found := 0
+	rtest.OK(t, dst.List(context.TODO(), restic.PackFile, func(fi backend.FileInfo) error {
+		found++
+		return nil
+	}))
+	rtest.Equals(t, len(want), found)
+
+	for name, content := range want {
+		data, err := backend.LoadAll(context.TODO(), nil, dst, backend.Handle{Type: restic.PackFile, Name: name})
+		rtest.OK(t, err)
+		rtest.Equals(t, content, string(data))
+	}
+}
+
+func TestRestoreToBackendInvalidTemplate(t *testing.T) {
+	repo := repository.TestRepository(t)
+	sn, _ := saveSnapshot(t, repo, Snapshot{
+		Nodes: map[string]Node{
+			"foo": File{Data: "content: foo\n"},
+		},
+	}, noopGetGenericAttributes)
+
+	err := RestoreToBackend(context.TODO(), repo, sn, mem.New(), "restore/file")
+	rtest.Assert(t, err != nil, "expected error for template without {path}")
+}
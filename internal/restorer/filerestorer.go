@@ -145,9 +145,10 @@ func (r *fileRestorer) restoreFiles(ctx context.Context) error {
 				file.sparse = r.sparse
 			}
 		})
-		if len(fileBlobs) == 1 {
+		if len(fileBlobs) == 1 || r.filesWriter.sparseMinHole > 0 {
 			// no need to preallocate files with a single block, thus we can always consider them to be sparse
 			// in addition, a short chunk will never match r.zeroChunk which would prevent sparseness for short files
+			// with a minimum hole size, runs of zeros are detected within all blobs and not just for zero chunks
 			file.sparse = r.sparse
 		}
 
@@ -16,6 +16,9 @@ import (
 // to use multiple os.File to write to the same target file
 type filesWriter struct {
 	buckets []filesWriterBucket
+	// sparseMinHole is the minimum length of a zero run skipped by sparse
+	// writes, see partialFile.WriteAt.
+	sparseMinHole int
 }
 
 type filesWriterBucket struct {
@@ -25,8 +28,9 @@ type filesWriterBucket struct {
 
 type partialFile struct {
 	*os.File
-	users  int // Reference count.
-	sparse bool
+	users   int // Reference count.
+	sparse  bool
+	minHole int
 }
 
 func newFilesWriter(count int) *filesWriter {
@@ -73,7 +77,7 @@ func (w *filesWriter) writeToFile(path string, blob []byte, offset int64, create
 			return nil, err
 		}
 
-		wr := &partialFile{File: f, users: 1, sparse: sparse}
+		wr := &partialFile{File: f, users: 1, sparse: sparse, minHole: w.sparseMinHole}
 		bucket.files[path] = wr
 
 		if createSize >= 0 {
@@ -2,10 +2,12 @@ package restorer
 
 import (
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"sync/atomic"
 
+	"github.com/minio/sha256-simd"
 	"github.com/restic/restic/internal/debug"
 	"github.com/restic/restic/internal/errors"
 	"github.com/restic/restic/internal/fs"
@@ -23,6 +25,11 @@ type Restorer struct {
 
 	progress *restoreui.Progress
 
+	// SparseMinHole is the minimum length of a run of zero bytes which is
+	// restored as a hole if sparse restores are enabled. If it is zero, only
+	// the leading zeros of each blob are skipped.
+	SparseMinHole int
+
 	Error        func(location string, err error) error
 	Warn         func(message string)
 	SelectFilter func(item string, dstpath string, node *restic.Node) (selectedForRestore bool, childMayBeSelected bool)
@@ -243,6 +250,7 @@ func (res *Restorer) RestoreTo(ctx context.Context, dst string) error {
 	filerestorer := newFileRestorer(dst, res.repo.LoadBlobsFromPack, res.repo.Index().Lookup,
 		res.repo.Connections(), res.sparse, res.progress)
 	filerestorer.Error = res.Error
+	filerestorer.filesWriter.sparseMinHole = res.SparseMinHole
 
 	debug.Log("first pass for %q", dst)
 
@@ -433,6 +441,19 @@ func (res *Restorer) verifyFile(target string, node *restic.Node, buf []byte) ([
 			target, node.Size, fi.Size())
 	}
 
+	if node.ContentHash != nil {
+		var match bool
+		buf, match, err = verifyContentHash(f, *node.ContentHash, buf)
+		if err != nil {
+			return buf, err
+		}
+		if match {
+			return buf, nil
+		}
+		// fall back to checking the individual blobs to report the offset
+		// of the unexpected content
+	}
+
 	var offset int64
 	for _, blobID := range node.Content {
 		length, found := res.repo.LookupBlobSize(blobID, restic.DataBlob)
@@ -457,5 +478,27 @@ func (res *Restorer) verifyFile(target string, node *restic.Node, buf []byte) ([
 		offset += int64(length)
 	}
 
+	if node.ContentHash != nil {
+		return buf, errors.Errorf("Unexpected content hash for %s", target)
+	}
+
 	return buf, nil
 }
+
+// verifyContentHash checks whether the SHA-256 hash of the content of f
+// matches the expected hash.
+func verifyContentHash(f *os.File, expected restic.ID, buf []byte) ([]byte, bool, error) {
+	const minBufSize = 1 << 20
+	if cap(buf) < minBufSize {
+		buf = make([]byte, minBufSize)
+	}
+	buf = buf[:cap(buf)]
+
+	h := sha256.New()
+	_, err := io.CopyBuffer(h, io.NewSectionReader(f, 0, 1<<63-1), buf)
+	if err != nil {
+		return buf, false, err
+	}
+
+	return buf, expected.Equal(restic.IDFromHash(h.Sum(nil))), nil
+}
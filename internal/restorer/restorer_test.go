@@ -893,3 +893,28 @@ func TestRestorerSparseFiles(t *testing.T) {
 	t.Logf("wrote %d zeros as %d blocks, %.1f%% sparse",
 		len(zeros), blocks, 100*sparsity)
 }
+
+func TestVerifyContentHash(t *testing.T) {
+	repo := repository.TestRepository(t)
+	res := NewRestorer(repo, nil, false, nil)
+
+	data := []byte("content: foo\n")
+	hash := restic.Hash(data)
+	node := &restic.Node{
+		Type: "file",
+		Size: uint64(len(data)),
+		// the blob is unknown, the check must rely on the content hash
+		Content:     restic.IDs{restic.NewRandomID()},
+		ContentHash: &hash,
+	}
+
+	filename := filepath.Join(rtest.TempDir(t), "foo")
+	rtest.OK(t, os.WriteFile(filename, data, 0600))
+	_, err := res.verifyFile(filename, node, nil)
+	rtest.OK(t, err)
+
+	// same size, different content
+	rtest.OK(t, os.WriteFile(filename, []byte("content: bar\n"), 0600))
+	_, err = res.verifyFile(filename, node, nil)
+	rtest.Assert(t, err != nil, "tampered file was not detected")
+}
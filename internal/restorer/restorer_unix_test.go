@@ -4,13 +4,17 @@
 package restorer
 
 import (
+	"bytes"
 	"context"
+	"io"
 	"os"
 	"path/filepath"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/restic/restic/internal/archiver"
+	"github.com/restic/restic/internal/fs"
 	"github.com/restic/restic/internal/repository"
 	"github.com/restic/restic/internal/restic"
 	rtest "github.com/restic/restic/internal/test"
@@ -121,3 +125,49 @@ func TestRestorerProgressBar(t *testing.T) {
 	rtest.Assert(t, mock.allBytesWritten == allBytesWritten, "allBytesWritten: expected %v, got %v", allBytesWritten, mock.allBytesWritten)
 	rtest.Assert(t, mock.allBytesTotal == allBytesTotal, "allBytesTotal: expected %v, got %v", allBytesTotal, mock.allBytesTotal)
 }
+
+func TestRestorerSparseMinHole(t *testing.T) {
+	// probe whether the filesystem supports holes at all
+	probe := filepath.Join(rtest.TempDir(t), "probe")
+	rtest.OK(t, os.WriteFile(probe, nil, 0600))
+	rtest.OK(t, os.Truncate(probe, 1<<20))
+	if getBlockCount(t, probe) != 0 {
+		t.Skip("filesystem does not support sparse files")
+	}
+
+	// random data with long runs of zeros which are not aligned to chunk
+	// boundaries, such that the zeros end up in the middle of blobs
+	data := rtest.Random(23, 8<<20)
+	for _, start := range []int{100000, 2500000, 5000000} {
+		copy(data[start:start+400<<10], make([]byte, 400<<10))
+	}
+
+	repo := repository.TestRepository(t)
+	target := &fs.Reader{
+		Mode:       0600,
+		Name:       "/holes",
+		ReadCloser: io.NopCloser(bytes.NewReader(data)),
+	}
+	arch := archiver.New(repo, target, archiver.Options{})
+	sn, _, _, err := arch.Snapshot(context.Background(), []string{"/holes"}, archiver.SnapshotOptions{})
+	rtest.OK(t, err)
+
+	restore := func(minHole int) int64 {
+		res := NewRestorer(repo, sn, true, nil)
+		res.SparseMinHole = minHole
+		tempdir := rtest.TempDir(t)
+		rtest.OK(t, res.RestoreTo(context.TODO(), tempdir))
+
+		filename := filepath.Join(tempdir, "holes")
+		content, err := os.ReadFile(filename)
+		rtest.OK(t, err)
+		rtest.Assert(t, bytes.Equal(data, content), "restored content differs")
+		return getBlockCount(t, filename)
+	}
+
+	dense := restore(0)
+	sparse := restore(64 << 10)
+	// each zero run must result in a hole of at least 300 KiB
+	rtest.Assert(t, (dense-sparse)*512 >= 3*300<<10,
+		"expected allocation to shrink by at least 900 KiB, got %d blocks vs. %d blocks", sparse, dense)
+}
@@ -4,6 +4,8 @@
 package restorer
 
 import (
+	"bytes"
+
 	"github.com/restic/restic/internal/restic"
 )
 
@@ -13,6 +15,9 @@ func (f *partialFile) WriteAt(p []byte, offset int64) (n int, err error) {
 	if !f.sparse {
 		return f.File.WriteAt(p, offset)
 	}
+	if f.minHole > 0 {
+		return f.writeAtSkipHoles(p, offset)
+	}
 
 	n = len(p)
 
@@ -35,3 +40,42 @@ func (f *partialFile) WriteAt(p []byte, offset int64) (n int, err error) {
 
 	return n, err
 }
+
+// writeAtSkipHoles writes p to f.File at offset, but skips all runs of zeros
+// which are at least f.minHole bytes long. The file was truncated to its
+// final size before, thus the skipped ranges read as zeros.
+func (f *partialFile) writeAtSkipHoles(p []byte, offset int64) (n int, err error) {
+	for len(p) > 0 {
+		start, end := findZeroRun(p, f.minHole)
+		if start > 0 {
+			n2, err := f.File.WriteAt(p[:start], offset)
+			n += n2
+			if err != nil {
+				return n, err
+			}
+		}
+		n += end - start
+		p = p[end:]
+		offset += int64(end)
+	}
+	return n, nil
+}
+
+// findZeroRun returns the position of the first run of zeros in p which is at
+// least minLen bytes long. If there is no such run, then start and end are
+// both len(p).
+func findZeroRun(p []byte, minLen int) (start, end int) {
+	for i := 0; i < len(p); {
+		idx := bytes.IndexByte(p[i:], 0)
+		if idx < 0 {
+			break
+		}
+		i += idx
+		zeros := restic.ZeroPrefixLen(p[i:])
+		if zeros >= minLen {
+			return i, i + zeros
+		}
+		i += zeros
+	}
+	return len(p), len(p)
+}
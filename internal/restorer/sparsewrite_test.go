@@ -0,0 +1,56 @@
+//go:build !windows
+// +build !windows
+
+package restorer
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	rtest "github.com/restic/restic/internal/test"
+)
+
+func TestFindZeroRun(t *testing.T) {
+	for _, test := range []struct {
+		data       []byte
+		minLen     int
+		start, end int
+	}{
+		{nil, 1, 0, 0},
+		{[]byte{1, 2, 3}, 1, 3, 3},
+		{[]byte{0, 0, 1}, 2, 0, 2},
+		{[]byte{0, 1, 0, 0, 0, 1}, 2, 2, 5},
+		{[]byte{1, 0, 0, 1, 0, 0, 0}, 3, 4, 7},
+		{[]byte{1, 0, 0, 1, 0, 0, 1}, 3, 7, 7},
+	} {
+		start, end := findZeroRun(test.data, test.minLen)
+		rtest.Equals(t, test.start, start)
+		rtest.Equals(t, test.end, end)
+	}
+}
+
+func TestPartialFileSkipHoles(t *testing.T) {
+	f, err := os.Create(filepath.Join(rtest.TempDir(t), "f"))
+	rtest.OK(t, err)
+	defer func() { rtest.OK(t, f.Close()) }()
+
+	data := rtest.Random(5, 10000)
+	copy(data[1000:3000], make([]byte, 2000))
+	copy(data[9000:], make([]byte, 1000))
+	// prefill the file to verify that the holes are not written
+	rtest.OK(t, os.WriteFile(f.Name(), bytes.Repeat([]byte{0xff}, 20000), 0600))
+
+	pf := &partialFile{File: f, sparse: true, minHole: 512}
+	n, err := pf.WriteAt(data, 100)
+	rtest.OK(t, err)
+	rtest.Equals(t, len(data), n)
+
+	buf, err := os.ReadFile(f.Name())
+	rtest.OK(t, err)
+	rtest.Assert(t, bytes.Equal(data[:1000], buf[100:1100]), "data before hole differs")
+	rtest.Assert(t, bytes.Equal(bytes.Repeat([]byte{0xff}, 2000), buf[1100:3100]), "hole was written")
+	rtest.Assert(t, bytes.Equal(data[3000:9000], buf[3100:9100]), "data after hole differs")
+	rtest.Assert(t, bytes.Equal(bytes.Repeat([]byte{0xff}, 1000), buf[9100:10100]), "trailing hole was written")
+}